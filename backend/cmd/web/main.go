@@ -13,7 +13,10 @@ import (
 	"github.com/chanaka-withanage/page-analyzer/internal/analyzer"
 	"github.com/chanaka-withanage/page-analyzer/internal/config"
 	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/fingerprint"
 	"github.com/chanaka-withanage/page-analyzer/internal/gateway"
+	"github.com/chanaka-withanage/page-analyzer/internal/jsvuln"
+	"github.com/chanaka-withanage/page-analyzer/pkg/extractor"
 )
 
 func main() {
@@ -25,6 +28,25 @@ func main() {
 	f := fetch.New(cfg.FetchTimeout, cfg.MaxRedirects, cfg.MaxBytes)
 	svc := analyzer.New(f)
 	svc.SetDefaultTimeout(cfg.FetchTimeout)
+	svc.SetStreamingParser(cfg.StreamingParser)
+
+	rules := fingerprint.Default()
+	if cfg.FingerprintRules != "" {
+		r, err := fingerprint.Load(cfg.FingerprintRules)
+		if err != nil {
+			slog.Error("failed to load fingerprint rules", "path", cfg.FingerprintRules, "err", err)
+			os.Exit(1)
+		}
+		rules = r
+	}
+	extractor.MustRegister(fingerprint.New(rules))
+
+	if db, err := jsvuln.Load(cfg.JSVulnDB); err != nil {
+		slog.Warn("js vulnerability db not loaded, library checks disabled", "path", cfg.JSVulnDB, "err", err)
+	} else {
+		svc.SetJSVulnDB(db)
+		slog.Info("js vulnerability db loaded", "path", cfg.JSVulnDB, "libraries", db.Len())
+	}
 
 	handler := gateway.NewMuxWithService(svc)
 
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/caching"
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/validity"
+	"github.com/chanaka-withanage/page-analyzer/internal/weight"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// maxCachedAssets bounds how many static assets are requested when
+// CheckAssetCaching is set.
+const maxCachedAssets = 20
+
+// docSize is the size of the HTML document: decoded, as transferred, and
+// estimated with gzip when it was sent uncompressed (0 otherwise).
+type docSize struct {
+	bytes, transfer, gzip int64
+}
+
+// readDocument parses body with the configured parser and measures the
+// document on the way through.
+func (s *Service) readDocument(resp *http.Response, body io.Reader, u *url.URL) (*parser.Parsed, validity.Report, docSize, error) {
+	parse := parser.Parse
+	if s.streaming {
+		parse = parser.ParseStream
+	}
+	counted := &countingReader{r: body}
+	var r io.Reader = counted
+	// Only a document sent without a content coding is compressed again, to
+	// estimate the saving; "identity" counts as none.
+	var gz *caching.GzipSize
+	if !caching.Parse(resp.Header, time.Now()).Compressed() {
+		gz = caching.NewGzipSize()
+		r = io.TeeReader(counted, gz)
+	}
+	parsed, valid, err := parseValidated(parse, r, u)
+
+	size := docSize{bytes: counted.n, transfer: counted.n}
+	if fb, ok := body.(*fetch.Body); ok {
+		size.transfer = fb.WireBytes()
+	}
+	if gz != nil {
+		size.gzip = gz.Size()
+	}
+	return parsed, valid, size, err
+}
+
+func (s *Service) cachingReport(ctx context.Context, p contract.AnalyzeParams, resp *http.Response, u *url.URL, parsed *parser.Parsed, size docSize) *contract.CachingReport {
+	doc := caching.Parse(resp.Header, time.Now())
+	rep := &contract.CachingReport{
+		Document: cachePolicy(u.String(), weight.TypeDocument, doc),
+		Findings: caching.Document(doc, size.bytes, size.gzip),
+	}
+	rep.Document.Bytes = size.bytes
+	rep.Document.TransferBytes = size.transfer
+	rep.Document.GzipBytes = size.gzip
+	if !p.CheckAssetCaching {
+		return rep
+	}
+
+	items := weight.Items(parsed.Resources)
+	if len(items) > maxCachedAssets {
+		items = items[:maxCachedAssets]
+	}
+	var assets []caching.Asset
+	for i, a := range s.fetchAssets(ctx, items, 4) {
+		if a.err != "" {
+			rep.Assets = append(rep.Assets, contract.CachePolicy{URL: items[i].URL, Kind: items[i].Type, Error: a.err})
+			continue
+		}
+		assets = append(assets, a.Asset)
+		cp := cachePolicy(a.URL, items[i].Type, a.Policy)
+		if a.Bytes >= 0 {
+			cp.TransferBytes = a.Bytes
+		}
+		rep.Assets = append(rep.Assets, cp)
+	}
+	rep.Findings = append(rep.Findings, caching.Assets(assets)...)
+	return rep
+}
+
+type fetchedAsset struct {
+	caching.Asset
+	err string
+}
+
+// fetchAssets requests each item through the guarded client, at most
+// concurrency at a time, and keeps the response headers. Bodies are not read.
+func (s *Service) fetchAssets(ctx context.Context, items []weight.Item, concurrency int) []fetchedAsset {
+	out := make([]fetchedAsset, len(items))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, it := range items {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				out[i].err = "context cancelled"
+				return
+			}
+			resp, _, err := s.fetch.Get(ctx, u)
+			if err != nil {
+				slog.Warn("asset fetch failed", "url", u, "err", err)
+				out[i].err = err.Error()
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				out[i].err = fmt.Sprintf("status %d", resp.StatusCode)
+				return
+			}
+			out[i].Asset = caching.Asset{
+				URL:         u,
+				ContentType: resp.Header.Get("Content-Type"),
+				Bytes:       resp.ContentLength,
+				Policy:      caching.Parse(resp.Header, time.Now()),
+			}
+		}(i, it.URL)
+	}
+	wg.Wait()
+	return out
+}
+
+func cachePolicy(u, kind string, p caching.Policy) contract.CachePolicy {
+	cp := contract.CachePolicy{
+		URL:             u,
+		Kind:            kind,
+		CacheControl:    p.CacheControl,
+		Expires:         p.Expires,
+		ETag:            p.ETag,
+		LastModified:    p.LastModified,
+		Vary:            p.Vary,
+		ContentEncoding: p.Encoding,
+		Cacheable:       p.Cacheable,
+	}
+	if p.TTL >= 0 {
+		secs := int64(p.TTL / time.Second)
+		cp.MaxAgeSeconds = &secs
+	}
+	return cp
+}
@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/conformance"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// conformanceReport checks the page against the requested doctype. An
+// unknown target is reported as a warning and HTML5 is used instead.
+func conformanceReport(p contract.AnalyzeParams, parsed *parser.Parsed, res *contract.AnalyzeResult) *contract.ConformanceReport {
+	target := p.ConformanceTarget
+	if !conformance.ValidTarget(target) {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("unknown conformance target %q, checked against %s", target, conformance.TargetHTML5))
+		target = conformance.TargetHTML5
+	}
+
+	rep := conformance.Check(parsed, target)
+	out := &contract.ConformanceReport{
+		Target:     rep.Target,
+		Declared:   rep.Declared,
+		Mode:       rep.Mode,
+		ModeReason: rep.ModeReason,
+		Obsolete:   []contract.ObsoleteMarkup{},
+		Migration:  rep.Migration,
+	}
+	for _, it := range rep.Items {
+		out.Obsolete = append(out.Obsolete, contract.ObsoleteMarkup{
+			Element:     it.Element,
+			Attribute:   it.Attribute,
+			Count:       it.Count,
+			Path:        it.Path,
+			Message:     it.Message,
+			Replacement: it.Replacement,
+		})
+	}
+	return out
+}
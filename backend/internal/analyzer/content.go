@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+	"github.com/patrickmn/go-cache"
+)
+
+// Content fetches a page and extracts its main content as Markdown. It
+// shares the fetch client, and so the SSRF guard, and the result cache with
+// Analyze. The tree parser is always used, since extraction needs the DOM.
+func (s *Service) Content(ctx context.Context, p contract.ContentParams) (*contract.ContentResult, error) {
+	key := "content|" + p.URL
+	if v, found := s.cache.Get(key); found {
+		if res, ok := v.(*contract.ContentResult); ok {
+			slog.Info("cache hit", "url", p.URL)
+			return res, nil
+		}
+	}
+
+	timeout := s.defaultTimeout
+	if p.FetchTimeoutSeconds > 0 {
+		timeout = time.Duration(p.FetchTimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res := &contract.ContentResult{URL: p.URL, Images: []contract.ContentImage{}}
+
+	resp, body, err := s.fetch.Get(ctx, p.URL)
+	if err != nil {
+		slog.Error("fetch failed", "url", p.URL, "err", err)
+		res.Errors = append(res.Errors, err.Error())
+		return res, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		slog.Warn("upstream returned non-2xx", "url", p.URL, "status", resp.StatusCode)
+		res.Errors = append(res.Errors, fmt.Sprintf("upstream status: %d", resp.StatusCode))
+		return res, fmt.Errorf("upstream returned %d", resp.StatusCode)
+	}
+
+	u, _ := url.Parse(p.URL)
+	u = fetch.FinalURL(resp, u)
+	parsed, err := parser.Parse(body, u)
+	if err != nil {
+		slog.Error("parse failed", "url", p.URL, "err", err)
+		res.Errors = append(res.Errors, err.Error())
+		return res, err
+	}
+	base := u
+	if b, err := url.Parse(parsed.BaseURL); err == nil {
+		base = b
+	}
+
+	c := parser.ExtractContent(parsed.Doc, base)
+	res.Title = c.Title
+	res.Byline = c.Byline
+	res.Markdown = c.Markdown
+	for _, img := range c.Images {
+		res.Images = append(res.Images, contract.ContentImage{URL: img.URL, Alt: img.Alt})
+	}
+
+	s.cache.Set(key, res, cache.DefaultExpiration)
+	slog.Info("cache store", "url", p.URL)
+	return res, nil
+}
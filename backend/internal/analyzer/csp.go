@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"github.com/chanaka-withanage/page-analyzer/internal/csp"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+const (
+	// maxInlineAttrs bounds each attribute list in the CSP report.
+	maxInlineAttrs = 50
+	// maxInlineValue is the length at which attribute values are cut.
+	maxInlineValue = 200
+)
+
+func cspReport(parsed *parser.Parsed) *contract.CSPReport {
+	rep := csp.Audit(parsed.InlineScripts, parsed.InlineCode, parsed.Resources)
+	out := &contract.CSPReport{
+		InlineScripts:     []contract.CSPInlineScript{},
+		EventHandlers:     inlineAttributes(rep.Handlers),
+		JavaScriptURLs:    inlineAttributes(rep.JSURLs),
+		InlineStyles:      inlineAttributes(rep.Styles),
+		ScriptsWithoutSRI: []string{},
+		Findings:          []contract.Finding{},
+	}
+	for _, s := range rep.Scripts {
+		out.InlineScripts = append(out.InlineScripts, contract.CSPInlineScript{
+			Type:  s.Type,
+			Bytes: s.Bytes,
+			Hash:  s.Hash,
+			Eval:  s.Eval,
+		})
+	}
+	out.ScriptsWithoutSRI = append(out.ScriptsWithoutSRI, rep.NoSRI...)
+	out.Findings = append(out.Findings, csp.Findings(rep)...)
+	return out
+}
+
+func inlineAttributes(list []parser.AttrValue) []contract.InlineAttribute {
+	out := []contract.InlineAttribute{}
+	for _, a := range list[:min(len(list), maxInlineAttrs)] {
+		v := []rune(a.Value)
+		if len(v) > maxInlineValue {
+			v = append(v[:maxInlineValue], '…')
+		}
+		out = append(out, contract.InlineAttribute{Element: a.Tag, Attribute: a.Name, Value: string(v)})
+	}
+	return out
+}
@@ -0,0 +1,22 @@
+package analyzer
+
+import (
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/pii"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func exposureReport(parsed *parser.Parsed) *contract.ExposureReport {
+	hits := pii.Scan(parsed.Text, parsed.Attributes, parsed.InlineScripts)
+	out := &contract.ExposureReport{
+		Findings: pii.Findings(hits),
+		Hits:     []contract.ExposureHit{},
+	}
+	if out.Findings == nil {
+		out.Findings = []contract.Finding{}
+	}
+	for _, h := range hits {
+		out.Hits = append(out.Hits, contract.ExposureHit{Kind: h.Kind, Value: h.Value, Location: h.Location})
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chanaka-withanage/page-analyzer/internal/selectors"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// extractedFields runs the caller's selectors against the document tree.
+// Without a tree, as with the streaming parser, every field reports why.
+func extractedFields(rules map[string]contract.ExtractRule, doc *goquery.Document) map[string]contract.ExtractedField {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make(map[string]contract.ExtractedField, len(rules))
+	if doc == nil {
+		for name := range rules {
+			out[name] = contract.ExtractedField{Error: "no document tree to run selectors against"}
+		}
+		return out
+	}
+
+	in := make(map[string]selectors.Rule, len(rules))
+	for name, r := range rules {
+		in[name] = selectors.Rule{Selector: r.Selector, Attr: r.Attr, Multiple: r.Multiple}
+	}
+	for name, f := range selectors.Extract(doc, in) {
+		field := contract.ExtractedField{Matches: f.Matches, Error: f.Err}
+		if rules[name].Multiple {
+			field.Values = f.Values
+		} else if len(f.Values) > 0 {
+			field.Value = f.Values[0]
+		}
+		out[name] = field
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func formsResult(forms []parser.Form) []contract.Form {
+	var out []contract.Form
+	for _, f := range forms {
+		cf := contract.Form{
+			Element:      f.Element,
+			Method:       f.Method,
+			Action:       f.Action,
+			Autocomplete: f.Autocomplete,
+			Inputs:       []contract.FormInput{},
+			HasPassword:  f.HasPassword,
+			HasCSRFToken: f.HasCSRFToken,
+		}
+		for _, in := range f.Inputs {
+			cf.Inputs = append(cf.Inputs, contract.FormInput{
+				Tag:          in.Tag,
+				Type:         in.Type,
+				Name:         in.Name,
+				Autocomplete: in.Autocomplete,
+			})
+		}
+		out = append(out, cf)
+	}
+	return out
+}
+
+// auditForms reports form security problems relative to docURL, the URL
+// the page was served from after redirects.
+func auditForms(docURL *url.URL, forms []parser.Form) []contract.Finding {
+	var findings []contract.Finding
+	add := func(f parser.Form, code, severity, msg string) {
+		findings = append(findings, contract.Finding{Code: code, Severity: severity, Message: msg, Element: f.Element})
+	}
+
+	pageHTTPS := strings.EqualFold(docURL.Scheme, "https")
+	for _, f := range forms {
+		action, err := url.Parse(f.Action)
+		if err != nil {
+			continue
+		}
+		webAction := action.Scheme == "http" || action.Scheme == "https"
+
+		if f.HasPassword && !pageHTTPS {
+			add(f, "password_form_over_http", contract.SeverityHigh,
+				"password form is served over plain http")
+		}
+		if f.HasPassword && pageHTTPS && action.Scheme == "http" {
+			add(f, "password_posted_over_http", contract.SeverityHigh,
+				"password form on an https page submits over plain http to "+f.Action)
+		}
+		if f.HasPassword && f.Method == "GET" {
+			add(f, "password_in_get_form", contract.SeverityHigh,
+				"password form uses GET, so credentials end up in the URL")
+		}
+		if webAction && !sameOrigin(docURL, action) {
+			sev := contract.SeverityWarning
+			if f.HasPassword {
+				sev = contract.SeverityHigh
+			}
+			add(f, "cross_origin_action", sev,
+				"form submits to a different origin: "+action.Scheme+"://"+action.Host)
+		}
+	}
+	return findings
+}
+
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && sameHost(a.Host, b.Host)
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/hreflang"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func (s *Service) hreflangReport(ctx context.Context, p contract.AnalyzeParams, u *url.URL, parsed *parser.Parsed) *contract.HreflangReport {
+	if len(parsed.Alternates) == 0 {
+		return nil
+	}
+
+	rep := &contract.HreflangReport{
+		Lang:     parsed.Lang,
+		Findings: hreflang.Validate(parsed.Alternates),
+	}
+	for _, a := range parsed.Alternates {
+		u := a.URL
+		if u == "" {
+			u = a.Href
+		}
+		rep.Alternates = append(rep.Alternates, contract.HreflangAlternate{Hreflang: a.Hreflang, URL: u})
+	}
+	if !p.CheckHreflang {
+		return rep
+	}
+
+	results := hreflang.Check(ctx, s.fetch, u.String(), parsed.Lang, parsed.Alternates, 4)
+	byURL := map[string]hreflang.Result{}
+	for _, r := range results {
+		byURL[r.Hreflang+" "+r.URL] = r
+	}
+	for i, a := range rep.Alternates {
+		r, ok := byURL[a.Hreflang+" "+a.URL]
+		if !ok {
+			continue
+		}
+		if r.Err != "" {
+			rep.Alternates[i].Error = r.Err
+			continue
+		}
+		reciprocal, matches := r.Reciprocal, r.LangMatches
+		rep.Alternates[i].Reciprocal = &reciprocal
+		rep.Alternates[i].Lang = r.Lang
+		rep.Alternates[i].LangMatches = &matches
+	}
+	rep.Findings = append(rep.Findings, hreflang.Findings(results)...)
+	return rep
+}
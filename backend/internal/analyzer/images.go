@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/images"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func (s *Service) imageReport(ctx context.Context, p contract.AnalyzeParams, parsed *parser.Parsed) *contract.ImageReport {
+	if len(parsed.Images) == 0 {
+		return nil
+	}
+
+	var sizes []images.Size
+	if p.FetchImages {
+		sizes = images.FetchSizes(ctx, s.fetch, images.URLs(parsed.Images), 4)
+	}
+	rep := &contract.ImageReport{
+		Count:    len(parsed.Images),
+		Findings: images.Check(parsed.Images, sizes),
+	}
+	for _, sz := range sizes {
+		a := contract.ImageAsset{URL: sz.URL, ContentType: sz.ContentType, Error: sz.Err}
+		if sz.Bytes >= 0 {
+			a.Bytes = sz.Bytes
+			a.Oversized = sz.Bytes >= images.OversizedBytes
+			rep.TotalBytes += sz.Bytes
+		}
+		rep.Assets = append(rep.Assets, a)
+	}
+	return rep
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/jsvuln"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// maxScannedScripts bounds how many external scripts are downloaded when
+// ScanScripts is set.
+const maxScannedScripts = 20
+
+func (s *Service) jsLibraries(ctx context.Context, p contract.AnalyzeParams, parsed *parser.Parsed) []contract.JSLibrary {
+	if s.jsdb == nil {
+		return nil
+	}
+
+	var scripts []jsvuln.Script
+	var pending []int
+	for _, r := range parsed.Resources {
+		if r.Kind != parser.KindScript {
+			continue
+		}
+		sc := jsvuln.Script{URL: r.URL}
+		if p.ScanScripts && len(pending) < maxScannedScripts && len(s.jsdb.Scan([]jsvuln.Script{sc})) == 0 {
+			pending = append(pending, len(scripts))
+		}
+		scripts = append(scripts, sc)
+	}
+	if len(pending) > 0 {
+		urls := make([]string, len(pending))
+		for i, idx := range pending {
+			urls[i] = scripts[idx].URL
+		}
+		content := jsvuln.FetchContent(ctx, s.fetch, urls, 4)
+		for _, idx := range pending {
+			scripts[idx].Content = content[scripts[idx].URL]
+		}
+	}
+	for _, is := range parsed.InlineScripts {
+		scripts = append(scripts, jsvuln.Script{Content: is.Content})
+	}
+
+	var out []contract.JSLibrary
+	for _, lib := range s.jsdb.Scan(scripts) {
+		l := contract.JSLibrary{
+			Name:      lib.Name,
+			Version:   lib.Version,
+			Detection: lib.Detection,
+			Script:    lib.Script,
+		}
+		for _, v := range lib.Vulnerabilities {
+			l.Vulnerabilities = append(l.Vulnerabilities, contract.JSVulnerability{
+				Severity:  v.Severity,
+				CVEs:      v.CVEs,
+				Summary:   v.Summary,
+				AtOrAbove: v.AtOrAbove,
+				Below:     v.Below,
+				Info:      v.Info,
+			})
+		}
+		out = append(out, l)
+	}
+	return out
+}
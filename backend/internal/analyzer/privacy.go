@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/privacy"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func privacyReport(rep privacy.Report) *contract.PrivacyReport {
+	out := &contract.PrivacyReport{
+		ThirdParties: []contract.ThirdParty{},
+		Categories:   map[string]int{},
+		Cookies:      []contract.Cookie{},
+		ConsentManager: contract.ConsentManager{
+			Present:  rep.ConsentManager.Present,
+			Name:     rep.ConsentManager.Name,
+			Evidence: rep.ConsentManager.Evidence,
+		},
+	}
+	for _, tp := range rep.ThirdParties {
+		out.Categories[tp.Category]++
+		out.ThirdParties = append(out.ThirdParties, contract.ThirdParty{
+			Domain:   tp.Domain,
+			Category: tp.Category,
+			Company:  tp.Company,
+			Hosts:    tp.Hosts,
+			Kinds:    tp.Kinds,
+			Requests: tp.Requests,
+		})
+	}
+	for _, c := range rep.Cookies {
+		ck := contract.Cookie{
+			Name:     c.Name,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: c.SameSite,
+		}
+		if !c.Expires.IsZero() {
+			ck.Expires = c.Expires.Format(time.RFC3339)
+		}
+		out.Cookies = append(out.Cookies, ck)
+	}
+	return out
+}
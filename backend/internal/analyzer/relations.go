@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/linkrel"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func (s *Service) relationsReport(ctx context.Context, p contract.AnalyzeParams, u *url.URL, parsed *parser.Parsed) *contract.RelationsReport {
+	rel := parsed.Relations
+	if !parsed.AMP && rel.AMPHTML == "" && rel.Next == "" && rel.Prev == "" {
+		return nil
+	}
+
+	rep := &contract.RelationsReport{
+		AMP:       parsed.AMP,
+		Canonical: rel.Canonical,
+		AMPHTML:   rel.AMPHTML,
+		Next:      rel.Next,
+		Prev:      rel.Prev,
+		Findings:  linkrel.Validate(u.String(), parsed.AMP, rel),
+	}
+	if !p.FollowRelations {
+		return rep
+	}
+
+	res := linkrel.Check(ctx, s.fetch, u.String(), parsed.AMP, rel)
+	rep.NextChain = res.NextChain
+	rep.PrevChain = res.PrevChain
+	rep.Findings = append(rep.Findings, res.Findings...)
+	return rep
+}
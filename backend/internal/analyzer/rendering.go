@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/rendering"
+	"github.com/chanaka-withanage/page-analyzer/internal/validity"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// maxRefreshHops bounds the meta refresh redirects followed in one analysis.
+const maxRefreshHops = 3
+
+// maxFollowedDelay is the meta refresh delay, in seconds, below which a
+// refresh counts as a redirect. Longer delays are usually slideshows or
+// session timeouts, listed but not followed.
+const maxFollowedDelay = 5
+
+// page is a fetched and parsed document.
+type page struct {
+	url    *url.URL
+	resp   *http.Response
+	parsed *parser.Parsed
+	valid  validity.Report
+	size   docSize
+}
+
+// load fetches and parses raw. The response body is read and closed.
+func (s *Service) load(ctx context.Context, raw string) (*page, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := s.fetch.Get(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("upstream returned %d", resp.StatusCode)
+	}
+
+	u = fetch.FinalURL(resp, u)
+	parsed, valid, size, err := s.readDocument(resp, body, u)
+	if err != nil {
+		return nil, err
+	}
+	return &page{url: u, resp: resp, parsed: parsed, valid: valid, size: size}, nil
+}
+
+// followRefresh follows meta refresh redirects from pg through the guarded
+// fetch client, up to maxRefreshHops and only for delays under
+// maxFollowedDelay, and returns the last page loaded with the URLs followed.
+// A hop that cannot be followed ends the chain with a warning.
+func (s *Service) followRefresh(ctx context.Context, res *contract.AnalyzeResult, pg *page) (*page, []string) {
+	var followed []string
+	seen := map[string]bool{pg.url.String(): true}
+	for {
+		r := pg.parsed.Refresh
+		if r == nil || r.Target == "" {
+			return pg, followed
+		}
+		switch {
+		case r.Delay >= maxFollowedDelay:
+			res.Warnings = append(res.Warnings, fmt.Sprintf("meta refresh to %q after %g seconds was not followed: only delays under %d seconds are treated as redirects", r.Target, r.Delay, maxFollowedDelay))
+			return pg, followed
+		case !strings.HasPrefix(r.URL, "http://") && !strings.HasPrefix(r.URL, "https://"):
+			res.Warnings = append(res.Warnings, fmt.Sprintf("meta refresh to %q was not followed: not an http(s) URL", r.Target))
+			return pg, followed
+		case seen[r.URL]:
+			res.Warnings = append(res.Warnings, fmt.Sprintf("meta refresh loop at %s", r.URL))
+			return pg, followed
+		case len(followed) == maxRefreshHops:
+			res.Warnings = append(res.Warnings, fmt.Sprintf("stopped after %d meta refresh redirects", maxRefreshHops))
+			return pg, followed
+		}
+
+		next, err := s.load(ctx, r.URL)
+		if err != nil {
+			slog.Warn("meta refresh target failed", "url", r.URL, "err", err)
+			res.Warnings = append(res.Warnings, fmt.Sprintf("meta refresh to %s failed: %v", r.URL, err))
+			return pg, followed
+		}
+		slog.Info("followed meta refresh", "from", pg.url.String(), "to", r.URL)
+		seen[r.URL] = true
+		followed = append(followed, r.URL)
+		pg = next
+	}
+}
+
+// renderingReport describes how the page renders and redirects, and warns
+// when the rest of the result is unlikely to reflect what users see.
+func renderingReport(p contract.AnalyzeParams, parsed *parser.Parsed, base *url.URL, followed []string, res *contract.AnalyzeResult) *contract.RenderingReport {
+	sh := rendering.DetectShell(parsed)
+	out := &contract.RenderingReport{
+		ClientRendered:  sh.Detected,
+		Framework:       sh.Framework,
+		Signals:         sh.Signals,
+		FollowedRefresh: followed,
+	}
+	if sh.Detected {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"page looks client-side rendered (%s); headings, links and text reflect the HTML before JavaScript runs",
+			strings.Join(sh.Signals, "; ")))
+	}
+
+	for _, r := range rendering.Redirects(parsed, base) {
+		out.Redirects = append(out.Redirects, contract.ClientRedirect{
+			Kind:         r.Kind,
+			Target:       r.Target,
+			URL:          r.URL,
+			DelaySeconds: r.Delay,
+		})
+		if r.Kind == rendering.KindMetaRefresh && !p.FollowRefresh && r.Delay < maxFollowedDelay {
+			res.Warnings = append(res.Warnings, fmt.Sprintf(
+				"page redirects to %s with a meta refresh; set follow_refresh to analyze the target", r.Target))
+		}
+	}
+	return out
+}
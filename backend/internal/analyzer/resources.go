@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"net/url"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/linkcheck"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func summarizeResources(list []parser.Resource) contract.ResourceSummary {
+	sum := contract.ResourceSummary{
+		ByKind:             map[string]int{},
+		InaccessibleByKind: map[string]int{},
+	}
+	for _, r := range list {
+		sum.Total++
+		sum.ByKind[r.Kind]++
+		if r.ThirdParty {
+			sum.ThirdParty++
+		} else {
+			sum.FirstParty++
+		}
+	}
+	return sum
+}
+
+// resourceTargets returns the URLs to validate for list, along with the
+// resources they belong to (entries that fail to parse are dropped from both).
+func resourceTargets(list []parser.Resource) ([]*url.URL, []parser.Resource) {
+	var urls []*url.URL
+	var kept []parser.Resource
+	for _, r := range list {
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			continue
+		}
+		urls = append(urls, u)
+		kept = append(kept, r)
+	}
+	return urls, kept
+}
+
+func recordBrokenResources(sum *contract.ResourceSummary, checked []parser.Resource, results []linkcheck.Result) {
+	for i, r := range results {
+		if r.Accessible {
+			continue
+		}
+		sum.Inaccessible++
+		sum.InaccessibleByKind[checked[i].Kind]++
+		sum.Broken = append(sum.Broken, contract.BrokenResource{
+			URL:        checked[i].URL,
+			Kind:       checked[i].Kind,
+			StatusCode: r.StatusCode,
+			Error:      r.Err,
+		})
+	}
+}
+
+func mixedContentResult(items []parser.MixedContent) *contract.MixedContentReport {
+	rep := &contract.MixedContentReport{
+		Active:  []contract.MixedContentItem{},
+		Passive: []contract.MixedContentItem{},
+	}
+	for _, m := range items {
+		item := contract.MixedContentItem{URL: m.URL, Kind: m.Kind, Tag: m.Tag, Path: m.Path}
+		if m.Active {
+			rep.Active = append(rep.Active, item)
+		} else {
+			rep.Passive = append(rep.Passive, item)
+		}
+	}
+	return rep
+}
@@ -2,16 +2,24 @@ package analyzer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/jsvuln"
 	"github.com/chanaka-withanage/page-analyzer/internal/linkcheck"
 	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/privacy"
+	"github.com/chanaka-withanage/page-analyzer/internal/textstats"
 	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+	"github.com/chanaka-withanage/page-analyzer/pkg/extractor"
 	"github.com/patrickmn/go-cache"
 )
 
@@ -19,6 +27,9 @@ type Service struct {
 	fetch          *fetch.Client
 	defaultTimeout time.Duration
 	cache          *cache.Cache
+	streaming      bool
+	extractors     *extractor.Registry
+	jsdb           *jsvuln.DB
 }
 
 func New(fetchClient *fetch.Client) *Service {
@@ -26,6 +37,7 @@ func New(fetchClient *fetch.Client) *Service {
         fetch: fetchClient,
         defaultTimeout: 5 * time.Minute,
         cache:          cache.New(5*time.Minute, 10*time.Minute), // default 5m TTL, purge every 10m
+		extractors:     extractor.Default,
 	}
 }
 
@@ -33,10 +45,29 @@ func (s *Service) SetDefaultTimeout(d time.Duration) {
 	s.defaultTimeout = d
 }
 
+// SetStreamingParser switches between the tree parser (default) and the
+// single-pass streaming parser, which uses less memory on large documents.
+func (s *Service) SetStreamingParser(on bool) {
+	s.streaming = on
+}
+
+// SetExtractors replaces the extractor registry, which defaults to
+// extractor.Default.
+func (s *Service) SetExtractors(r *extractor.Registry) {
+	s.extractors = r
+}
+
+// SetJSVulnDB enables detection of vulnerable JavaScript libraries against
+// db. Detection is off while no database is set.
+func (s *Service) SetJSVulnDB(db *jsvuln.DB) {
+	s.jsdb = db
+}
+
 func (s *Service) Analyze(ctx context.Context, p contract.AnalyzeParams) (*contract.AnalyzeResult, error) {
 
     // check cache
-    if v, found := s.cache.Get(p.URL); found {
+    key := cacheKey(p)
+    if v, found := s.cache.Get(key); found {
         if res, ok := v.(*contract.AnalyzeResult); ok {
             slog.Info("cache hit", "url", p.URL)
             return res, nil
@@ -77,17 +108,58 @@ func (s *Service) Analyze(ctx context.Context, p contract.AnalyzeParams) (*contr
 	}
 
 	u, _ := url.Parse(p.URL)
-	parsed, err := parser.Parse(body, u)
+	u = fetch.FinalURL(resp, u)
+	parsed, valid, size, err := s.readDocument(resp, body, u)
 	if err != nil {
 		slog.Error("parse failed", "url", p.URL, "err", err)
 		res.Errors = append(res.Errors, err.Error())
 		return res, err
 	}
 
+	var followed []string
+	if p.FollowRefresh {
+		var pg *page
+		pg, followed = s.followRefresh(ctx, res, &page{url: u, resp: resp, parsed: parsed, valid: valid, size: size})
+		u, resp, parsed, valid, size = pg.url, pg.resp, pg.parsed, pg.valid, pg.size
+	}
+
+	base := u
+	if b, err := url.Parse(parsed.BaseURL); err == nil {
+		base = b
+	}
+	if parsed.Doc == nil {
+		res.Warnings = append(res.Warnings, "streaming parser: checks that need the document tree were skipped")
+	}
+
 	res.HTMLVersion = parsed.HTMLVersion
+	res.BaseURL = parsed.BaseURL
 	res.Title = parsed.Title
 	res.Headings = parsed.Headings
 	res.LoginFormPresent = parsed.LoginFormPresent
+	res.Login = loginInfo(parsed.Login)
+	res.Forms = formsResult(parsed.Forms)
+	res.FormFindings = auditForms(u, parsed.Forms)
+	if strings.EqualFold(u.Scheme, "https") {
+		if parsed.Doc != nil {
+			res.MixedContent = mixedContentResult(parser.FindMixedContent(parsed.Doc, base))
+		} else {
+			res.MixedContent = mixedContentResult(parser.MixedContentFromResources(parsed.Resources, parsed.Forms))
+		}
+	}
+	res.Hreflang = s.hreflangReport(ctx, p, u, parsed)
+	res.JSLibraries = s.jsLibraries(ctx, p, parsed)
+	res.Privacy = privacyReport(privacy.Audit(u, parsed.Resources, parsed.InlineScripts, resp.Header))
+	res.Exposure = exposureReport(parsed)
+	res.CSP = cspReport(parsed)
+	res.Conformance = conformanceReport(p, parsed, res)
+	res.Validity = validityReport(valid)
+	res.Rendering = renderingReport(p, parsed, base, followed, res)
+	res.Images = s.imageReport(ctx, p, parsed)
+	res.Caching = s.cachingReport(ctx, p, resp, u, parsed, size)
+	res.SiteFiles = s.siteFilesReport(ctx, p, u, parsed)
+	res.Relations = s.relationsReport(ctx, p, u, parsed)
+	res.ContentDigest = contentDigest(parsed.Text)
+	res.Text = textStats(textstats.Analyze(parsed.Text, parsed.Lang, size.bytes, p.TopTerms))
 
 	host := u.Host
 	var urlObjs []*url.URL
@@ -104,21 +176,32 @@ func (s *Service) Analyze(ctx context.Context, p contract.AnalyzeParams) (*contr
 		}
 	}
 
-	if len(urlObjs) > 0 {
-		slog.Debug("validating links", "url", p.URL, "count", len(urlObjs))
-		checker := linkcheck.New(10, 2, s.defaultTimeout/2)
-		results := checker.Validate(ctx, urlObjs)
+	res.Resources = summarizeResources(parsed.Resources)
+	resURLs, checked := resourceTargets(parsed.Resources)
+	var resResults []linkcheck.Result
+
+	targets := append(urlObjs[:len(urlObjs):len(urlObjs)], resURLs...)
+	if len(targets) > 0 {
+		slog.Debug("validating links", "url", p.URL, "links", len(urlObjs), "resources", len(resURLs))
+		results := s.linkChecker().Validate(ctx, targets)
 
 		bad := 0
-		for _, r := range results {
+		for _, r := range results[:len(urlObjs)] {
 			if !r.Accessible {
 				bad++
 			}
 		}
 		res.LinksInaccessible = bad
-		slog.Info("link validation complete", "url", p.URL, "bad_links", bad)
+		resResults = results[len(urlObjs):]
+		recordBrokenResources(&res.Resources, checked, resResults)
+		slog.Info("link validation complete", "url", p.URL, "bad_links", bad, "bad_resources", res.Resources.Inaccessible)
 	}
 
+	res.Weight = s.pageWeight(ctx, parsed, size.transfer, checked, resResults)
+
+	res.Extracted = extractedFields(p.Extract, parsed.Doc)
+	s.runExtractors(ctx, res, resp, u, base, parsed.Doc)
+
 	slog.Info("analysis finished",
 		"url", p.URL,
 		"duration_ms", time.Since(start).Milliseconds(),
@@ -128,13 +211,104 @@ func (s *Service) Analyze(ctx context.Context, p contract.AnalyzeParams) (*contr
 
     // Add to cache
     if err == nil {
-        s.cache.Set(p.URL, res, cache.DefaultExpiration)
+        s.cache.Set(key, res, cache.DefaultExpiration)
         slog.Info("cache store", "url", p.URL)
     }
 
 	return res, nil
 }
 
+// cacheKey identifies a cached result. Options that change the output are
+// part of the key.
+func cacheKey(p contract.AnalyzeParams) string {
+	key := p.URL
+	if p.CheckHreflang {
+		key += "|hreflang"
+	}
+	if p.ScanScripts {
+		key += "|scripts"
+	}
+	if p.TopTerms > 0 {
+		key += "|terms=" + strconv.Itoa(p.TopTerms)
+	}
+	if p.FollowRefresh {
+		key += "|follow"
+	}
+	if p.ConformanceTarget != "" {
+		key += "|target=" + p.ConformanceTarget
+	}
+	if p.FetchImages {
+		key += "|images"
+	}
+	if p.CheckAssetCaching {
+		key += "|assetcache"
+	}
+	if p.CheckSiteFiles {
+		key += "|sitefiles"
+	}
+	if p.FollowRelations {
+		key += "|relations"
+	}
+	if len(p.Extract) > 0 {
+		// Map keys are encoded in sorted order, so equal rules give equal keys.
+		rules, _ := json.Marshal(p.Extract)
+		key += "|extract=" + string(rules)
+	}
+	return key
+}
+
+func loginInfo(d parser.LoginDetection) *contract.LoginInfo {
+	if len(d.Candidates) == 0 {
+		return nil
+	}
+	info := &contract.LoginInfo{
+		Kind:       d.Kind,
+		Confidence: d.Confidence,
+		Element:    d.Element,
+		Reasons:    d.Reasons,
+	}
+	for _, c := range d.Candidates {
+		info.Candidates = append(info.Candidates, contract.LoginCandidate{
+			Kind:       c.Kind,
+			Confidence: c.Confidence,
+			Element:    c.Element,
+			Reasons:    c.Reasons,
+		})
+	}
+	return info
+}
+
+// linkChecker returns a checker for page links and subresources that goes
+// through the fetch client's address guard.
+func (s *Service) linkChecker() *linkcheck.Checker {
+	return linkcheck.New(10, 2, s.defaultTimeout/2).WithTransport(s.fetch.Transport())
+}
+
 func sameHost(a, b string) bool {
 	return strings.EqualFold(a, b)
 }
+
+func (s *Service) runExtractors(ctx context.Context, res *contract.AnalyzeResult, resp *http.Response, docURL, base *url.URL, doc *goquery.Document) {
+	if s.extractors == nil || s.extractors.Len() == 0 {
+		return
+	}
+
+	finalURL := fetch.FinalURL(resp, docURL)
+
+	sections, errs := s.extractors.Run(ctx, &extractor.Input{
+		Doc: doc,
+		Response: extractor.Response{
+			URL:        finalURL,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+		},
+		BaseURL: base,
+	})
+	for _, err := range errs {
+		slog.Warn("extractor failed", "url", docURL.String(), "err", err)
+		res.Warnings = append(res.Warnings, err.Error())
+	}
+	if len(sections) > 0 {
+		res.Sections = sections
+	}
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/similarity"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func contentDigest(text string) *contract.ContentDigest {
+	d := similarity.Compute(text)
+	return &contract.ContentDigest{
+		SHA256:  d.SHA256,
+		SimHash: similarity.FormatSimHash(d.SimHash),
+		Words:   d.Words,
+	}
+}
+
+// Similarity compares the visible text of two pages. URLs are fetched
+// through the guarded client and parsed; stored digests are used as given.
+func (s *Service) Similarity(ctx context.Context, p contract.SimilarityParams) (*contract.SimilarityResult, error) {
+	timeout := s.defaultTimeout
+	if p.FetchTimeoutSeconds > 0 {
+		timeout = time.Duration(p.FetchTimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res := &contract.SimilarityResult{
+		A: contract.SimilarityPage{URL: p.A.URL, Digest: p.A.Digest},
+		B: contract.SimilarityPage{URL: p.B.URL, Digest: p.B.Digest},
+	}
+	for _, pg := range []*contract.SimilarityPage{&res.A, &res.B} {
+		if pg.Digest != nil {
+			continue
+		}
+		loaded, err := s.load(ctx, pg.URL)
+		if err != nil {
+			slog.Error("similarity fetch failed", "url", pg.URL, "err", err)
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", pg.URL, err))
+			return res, err
+		}
+		pg.Digest = contentDigest(loaded.parsed.Text)
+	}
+
+	// Pages without text all share the empty digest; that says nothing
+	// about their similarity.
+	if res.A.Digest.Words == 0 || res.B.Digest.Words == 0 {
+		return res, nil
+	}
+	res.Comparable = true
+
+	a, err := similarity.ParseSimHash(res.A.Digest.SimHash)
+	if err != nil {
+		return res, fmt.Errorf("invalid simhash %q", res.A.Digest.SimHash)
+	}
+	b, err := similarity.ParseSimHash(res.B.Digest.SimHash)
+	if err != nil {
+		return res, fmt.Errorf("invalid simhash %q", res.B.Digest.SimHash)
+	}
+	res.Distance = similarity.Distance(a, b)
+	res.Similarity = similarity.Percent(res.Distance)
+	res.Identical = res.A.Digest.SHA256 != "" && res.A.Digest.SHA256 == res.B.Digest.SHA256
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/sitefiles"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+func (s *Service) siteFilesReport(ctx context.Context, p contract.AnalyzeParams, u *url.URL, parsed *parser.Parsed) *contract.SiteFilesReport {
+	if !p.CheckSiteFiles {
+		return nil
+	}
+
+	files := sitefiles.Discover(ctx, s.fetch, u, parsed.Feeds, parsed.Manifest, 4, time.Now())
+	rep := &contract.SiteFilesReport{Findings: sitefiles.Findings(files)}
+	for _, f := range files {
+		rep.Files = append(rep.Files, contract.SiteFile{
+			Kind:     f.Kind,
+			URL:      f.URL,
+			Source:   f.Source,
+			Present:  f.Present,
+			Valid:    f.Valid,
+			Status:   f.Status,
+			Error:    f.Err,
+			Problems: f.Problems,
+			Fields:   f.Fields,
+		})
+	}
+	return rep
+}
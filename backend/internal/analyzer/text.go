@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"io"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/textstats"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// countingReader counts the bytes read through it, which gives the size of
+// the HTML document as the parser consumed it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func textStats(st textstats.Stats) *contract.TextStats {
+	out := &contract.TextStats{
+		Words:         st.Words,
+		Sentences:     st.Sentences,
+		TextHTMLRatio: st.TextHTMLRatio,
+	}
+	if st.English && st.Words > 0 {
+		ease, grade := st.FleschReadingEase, st.FleschKincaidGrade
+		out.FleschReadingEase = &ease
+		out.FleschKincaidGrade = &grade
+	}
+	for _, t := range st.TopTerms {
+		out.TopTerms = append(out.TopTerms, contract.TermCount{Term: t.Term, Count: t.Count})
+	}
+	return out
+}
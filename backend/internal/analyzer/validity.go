@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"io"
+	"log/slog"
+	"net/url"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/validity"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+type parseFunc func(io.Reader, *url.URL) (*parser.Parsed, error)
+
+// parseValidated runs parse over r while the validity checks tokenize the
+// same bytes through a pipe, so the body is still read once and never
+// buffered whole.
+func parseValidated(parse parseFunc, r io.Reader, u *url.URL) (*parser.Parsed, validity.Report, error) {
+	pr, pw := io.Pipe()
+	type checked struct {
+		rep validity.Report
+		err error
+	}
+	done := make(chan checked, 1)
+	go func() {
+		rep, err := validity.Check(pr)
+		done <- checked{rep, err}
+	}()
+
+	parsed, err := parse(io.TeeReader(r, pw), u)
+	pw.CloseWithError(err)
+	v := <-done
+	if v.err != nil && err == nil {
+		slog.Warn("validity checks failed", "url", u.String(), "err", v.err)
+	}
+	return parsed, v.rep, err
+}
+
+func validityReport(rep validity.Report) *contract.ValidityReport {
+	out := &contract.ValidityReport{Errors: []contract.ValidityError{}, Total: rep.Total}
+	for _, p := range rep.Problems {
+		out.Errors = append(out.Errors, contract.ValidityError{
+			Code:    p.Code,
+			Message: p.Message,
+			Line:    p.Line,
+			Column:  p.Column,
+		})
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/linkcheck"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/weight"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// pageWeight estimates the page weight from the sizes reported while
+// validating resources. Sizes the HEAD requests did not report are asked
+// for with a ranged GET; broken resources are not requested again.
+func (s *Service) pageWeight(ctx context.Context, parsed *parser.Parsed, docBytes int64, checked []parser.Resource, results []linkcheck.Result) *contract.PageWeight {
+	checkedByURL := map[string]linkcheck.Result{}
+	for i, r := range results {
+		checkedByURL[checked[i].URL] = r
+	}
+
+	items := weight.Items(parsed.Resources)
+	var missing []int
+	var missingURLs []*url.URL
+	for i := range items {
+		r, ok := checkedByURL[items[i].URL]
+		switch {
+		case ok && !r.Accessible:
+			continue
+		case ok && r.Bytes >= 0:
+			items[i].Bytes = r.Bytes
+			continue
+		}
+		if u, err := url.Parse(items[i].URL); err == nil {
+			missing = append(missing, i)
+			missingURLs = append(missingURLs, u)
+		}
+	}
+	if len(missingURLs) > 0 {
+		sizes := s.linkChecker().Sizes(ctx, missingURLs)
+		for j, i := range missing {
+			items[i].Bytes = sizes[j]
+		}
+	}
+	items = append(items, weight.Item{Type: weight.TypeDocument, Bytes: docBytes})
+
+	rep := weight.Summarize(items)
+	out := &contract.PageWeight{
+		TotalBytes:     rep.Total.Bytes,
+		Requests:       rep.Total.Requests,
+		ByType:         map[string]contract.WeightBucket{},
+		FirstParty:     weightBucket(rep.FirstParty),
+		ThirdParty:     weightBucket(rep.ThirdParty),
+		UnknownSize:    rep.Unknown,
+		RenderBlocking: []contract.RenderBlocking{},
+	}
+	for t, b := range rep.ByType {
+		out.ByType[t] = weightBucket(b)
+	}
+	for _, r := range parsed.RenderBlocking {
+		reason := "stylesheet without a media query blocks rendering until it loads"
+		if r.Kind == parser.KindScript {
+			reason = "synchronous script in <head> blocks parsing; add async or defer"
+		}
+		out.RenderBlocking = append(out.RenderBlocking, contract.RenderBlocking{
+			URL:        r.URL,
+			Kind:       r.Kind,
+			ThirdParty: r.ThirdParty,
+			Reason:     reason,
+		})
+	}
+	return out
+}
+
+func weightBucket(b weight.Bucket) contract.WeightBucket {
+	return contract.WeightBucket{Requests: b.Requests, Bytes: b.Bytes}
+}
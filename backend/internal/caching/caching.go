@@ -0,0 +1,232 @@
+// Package caching audits the HTTP caching and compression headers of a page
+// and its static assets: whether browsers may reuse a response and for how
+// long, whether it can be revalidated, and whether it is compressed.
+package caching
+
+import (
+	"compress/gzip"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+const (
+	// minCompressBytes is the size below which compression is not worth
+	// flagging.
+	minCompressBytes = 1 << 10
+	// minAssetLifetime is the freshness lifetime below which a static asset
+	// is reported as short-lived.
+	minAssetLifetime = 7 * 24 * time.Hour
+)
+
+// Policy is what the response headers say about caching and compression.
+type Policy struct {
+	CacheControl string
+	Expires      string
+	ETag         string
+	LastModified string
+	Vary         string
+	Encoding     string
+
+	// Cacheable reports whether a browser may reuse the response without
+	// revalidating it first. Reason explains why not.
+	Cacheable bool
+	Reason    string
+	// TTL is the explicit freshness lifetime, or -1 when the response
+	// declares none and caches fall back on heuristics.
+	TTL time.Duration
+}
+
+// Parse reads the caching headers of a response received at now.
+func Parse(h http.Header, now time.Time) Policy {
+	p := Policy{
+		CacheControl: strings.Join(h.Values("Cache-Control"), ", "),
+		Expires:      h.Get("Expires"),
+		ETag:         h.Get("ETag"),
+		LastModified: h.Get("Last-Modified"),
+		Vary:         strings.Join(h.Values("Vary"), ", "),
+		Encoding:     strings.ToLower(strings.TrimSpace(h.Get("Content-Encoding"))),
+		TTL:          -1,
+	}
+
+	directives := map[string]string{}
+	for _, d := range strings.Split(p.CacheControl, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if k != "" {
+			directives[strings.ToLower(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	if v, ok := directives["max-age"]; ok {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+			p.TTL = time.Duration(secs) * time.Second
+		} else {
+			p.TTL = 0
+		}
+	} else if p.Expires != "" {
+		// Invalid dates, such as "0", mean already expired.
+		p.TTL = 0
+		if t, err := http.ParseTime(p.Expires); err == nil && t.After(now) {
+			p.TTL = t.Sub(now)
+		}
+	}
+
+	_, noStore := directives["no-store"]
+	_, noCache := directives["no-cache"]
+	switch {
+	case noStore:
+		p.Reason = "Cache-Control: no-store"
+	case noCache:
+		p.Reason = "Cache-Control: no-cache forces revalidation"
+	case strings.TrimSpace(p.Vary) == "*":
+		p.Reason = "Vary: * prevents reuse"
+	case p.TTL == 0:
+		p.Reason = "already expired"
+	case p.TTL < 0:
+		p.Reason = "no max-age or Expires"
+	default:
+		p.Cacheable = true
+	}
+	return p
+}
+
+// Validator reports whether the response can be revalidated with a
+// conditional request.
+func (p Policy) Validator() bool {
+	return p.ETag != "" || p.LastModified != ""
+}
+
+// Compressed reports whether the response was sent with a content coding.
+func (p Policy) Compressed() bool {
+	return p.Encoding != "" && p.Encoding != "identity"
+}
+
+// Compressible reports whether a content type benefits from compression.
+// Images other than SVG, fonts in WOFF and media are compressed already.
+func Compressible(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mt, "text/"),
+		strings.HasSuffix(mt, "+xml"), strings.HasSuffix(mt, "+json"),
+		strings.HasSuffix(mt, "/xml"), strings.HasSuffix(mt, "/json"),
+		strings.HasSuffix(mt, "javascript"), strings.HasSuffix(mt, "ecmascript"),
+		mt == "font/ttf", mt == "font/otf", mt == "application/vnd.ms-fontobject":
+		return true
+	}
+	return false
+}
+
+// Document reports the caching and compression findings for the HTML
+// document. size is its decoded size and gzipSize the estimated size with
+// gzip, or 0 when it was sent compressed.
+func Document(p Policy, size, gzipSize int64) []contract.Finding {
+	var findings []contract.Finding
+	if !p.Compressed() && size >= minCompressBytes {
+		msg := fmt.Sprintf("HTML is sent uncompressed (%d KiB)", size>>10)
+		if gzipSize > 0 {
+			msg += fmt.Sprintf("; gzip would bring it to about %d KiB", gzipSize>>10)
+		}
+		findings = append(findings, contract.Finding{Code: "html_not_compressed", Severity: contract.SeverityWarning, Message: msg})
+	}
+	if !p.Validator() {
+		findings = append(findings, contract.Finding{Code: "no_validator", Severity: contract.SeverityInfo,
+			Message: "the document has no ETag or Last-Modified, so revisits download it in full"})
+	}
+	if p.CacheControl == "" {
+		findings = append(findings, contract.Finding{Code: "no_cache_control", Severity: contract.SeverityInfo,
+			Message: "the document has no Cache-Control header; caches fall back on heuristics"})
+	}
+	if strings.TrimSpace(p.Vary) == "*" {
+		findings = append(findings, contract.Finding{Code: "vary_star", Severity: contract.SeverityWarning,
+			Message: "Vary: * prevents the document from being reused from any cache"})
+	}
+	return findings
+}
+
+// Asset is a fetched static asset.
+type Asset struct {
+	URL         string
+	ContentType string
+	// Bytes is the transfer size, or -1 when unknown.
+	Bytes  int64
+	Policy Policy
+}
+
+// Assets reports the caching and compression findings for static assets,
+// one finding per problem with an example.
+func Assets(assets []Asset) []contract.Finding {
+	var notCacheable, short, notCompressed, noValidator []string
+	for _, a := range assets {
+		p := a.Policy
+		switch {
+		case !p.Cacheable:
+			notCacheable = append(notCacheable, fmt.Sprintf("%s (%s)", a.URL, p.Reason))
+			if !p.Validator() {
+				noValidator = append(noValidator, a.URL)
+			}
+		case p.TTL < minAssetLifetime:
+			short = append(short, fmt.Sprintf("%s (%s)", a.URL, p.TTL))
+		}
+		if !p.Compressed() && Compressible(a.ContentType) && (a.Bytes < 0 || a.Bytes >= minCompressBytes) {
+			notCompressed = append(notCompressed, a.URL)
+		}
+	}
+
+	var findings []contract.Finding
+	summarize := func(code, severity string, list []string, what string) {
+		if len(list) == 0 {
+			return
+		}
+		findings = append(findings, contract.Finding{Code: code, Severity: severity,
+			Message: fmt.Sprintf("%d static asset(s) %s, e.g. %s", len(list), what, list[0])})
+	}
+	summarize("static_not_cacheable", contract.SeverityWarning, notCacheable,
+		"are not cacheable without revalidation")
+	summarize("short_cache_lifetime", contract.SeverityInfo, short,
+		"are cached for less than a week; fingerprinted files can be cached for a year")
+	summarize("asset_not_compressed", contract.SeverityWarning, notCompressed,
+		"are text-based but sent uncompressed")
+	summarize("no_validator", contract.SeverityInfo, noValidator,
+		"have neither a cache lifetime nor an ETag or Last-Modified")
+	return findings
+}
+
+// GzipSize counts the bytes written to it after gzip compression, to
+// estimate what compression would save on a response sent without it.
+type GzipSize struct {
+	w *gzip.Writer
+	n byteCounter
+}
+
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// NewGzipSize returns a GzipSize at the default compression level, the one
+// servers commonly use.
+func NewGzipSize() *GzipSize {
+	g := &GzipSize{}
+	g.w = gzip.NewWriter(&g.n)
+	return g
+}
+
+// Write compresses p and discards the output, keeping only its size.
+func (g *GzipSize) Write(p []byte) (int, error) {
+	return g.w.Write(p)
+}
+
+// Size flushes the compressor and returns the compressed size.
+func (g *GzipSize) Size() int64 {
+	_ = g.w.Close()
+	return int64(g.n)
+}
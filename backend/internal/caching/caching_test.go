@@ -0,0 +1,116 @@
+package caching_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/caching"
+)
+
+func header(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i < len(kv); i += 2 {
+		h.Add(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		h         http.Header
+		cacheable bool
+		ttl       time.Duration
+	}{
+		{"max-age", header("Cache-Control", "public, max-age=31536000, immutable"), true, 365 * 24 * time.Hour},
+		{"max-age wins over expires", header("Cache-Control", "max-age=60", "Expires", "Thu, 01 Jan 2026 00:00:00 GMT"), true, time.Minute},
+		{"expires", header("Expires", "Fri, 02 Jan 2026 00:00:00 GMT"), true, 24 * time.Hour},
+		{"invalid expires", header("Expires", "0"), false, 0},
+		{"no-store", header("Cache-Control", "no-store, max-age=600"), false, 10 * time.Minute},
+		{"no-cache", header("Cache-Control", "no-cache"), false, -1},
+		{"vary star", header("Cache-Control", "max-age=60", "Vary", "*"), false, time.Minute},
+		{"nothing", header(), false, -1},
+	}
+	for _, tc := range cases {
+		p := caching.Parse(tc.h, now)
+		if p.Cacheable != tc.cacheable || p.TTL != tc.ttl {
+			t.Errorf("%s: expected cacheable=%v ttl=%v, got %v %v (%s)", tc.name, tc.cacheable, tc.ttl, p.Cacheable, p.TTL, p.Reason)
+		}
+	}
+}
+
+func TestCompressible(t *testing.T) {
+	for ct, want := range map[string]bool{
+		"text/html; charset=utf-8": true,
+		"application/javascript":   true,
+		"image/svg+xml":            true,
+		"application/json":         true,
+		"image/png":                false,
+		"font/woff2":               false,
+		"":                         false,
+	} {
+		if got := caching.Compressible(ct); got != want {
+			t.Errorf("Compressible(%q) = %v, want %v", ct, got, want)
+		}
+	}
+}
+
+func TestDocument(t *testing.T) {
+	p := caching.Parse(header("Content-Type", "text/html"), time.Now())
+	codes := map[string]string{}
+	for _, f := range caching.Document(p, 50<<10, 10<<10) {
+		codes[f.Code] = f.Message
+	}
+	if !strings.Contains(codes["html_not_compressed"], "about 10 KiB") {
+		t.Errorf("expected an uncompressed HTML finding with the gzip estimate, got %v", codes)
+	}
+	if _, ok := codes["no_validator"]; !ok {
+		t.Errorf("expected a no_validator finding, got %v", codes)
+	}
+
+	p = caching.Parse(header("Content-Encoding", "gzip", "ETag", `"abc"`, "Cache-Control", "no-cache"), time.Now())
+	if f := caching.Document(p, 50<<10, 0); len(f) != 0 {
+		t.Errorf("expected no findings, got %+v", f)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	now := time.Now()
+	findings := caching.Assets([]caching.Asset{
+		{URL: "https://x.test/app.js", ContentType: "text/javascript", Bytes: 40 << 10,
+			Policy: caching.Parse(header("Cache-Control", "no-store"), now)},
+		{URL: "https://x.test/site.css", ContentType: "text/css", Bytes: 5 << 10,
+			Policy: caching.Parse(header("Cache-Control", "max-age=3600", "Content-Encoding", "br"), now)},
+		{URL: "https://x.test/logo.png", ContentType: "image/png", Bytes: 40 << 10,
+			Policy: caching.Parse(header("Cache-Control", "max-age=31536000"), now)},
+	})
+
+	got := map[string]string{}
+	for _, f := range findings {
+		got[f.Code] = f.Message
+	}
+	for code, example := range map[string]string{
+		"static_not_cacheable": "app.js (Cache-Control: no-store)",
+		"short_cache_lifetime": "site.css (1h0m0s)",
+		"asset_not_compressed": "app.js",
+		"no_validator":         "app.js",
+	} {
+		if !strings.Contains(got[code], example) {
+			t.Errorf("expected %s to mention %q, got %q", code, example, got[code])
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("expected 4 findings, got %v", got)
+	}
+}
+
+func TestGzipSize(t *testing.T) {
+	g := caching.NewGzipSize()
+	g.Write([]byte(strings.Repeat("<div>repetitive</div>", 1000)))
+	if n := g.Size(); n == 0 || n > 1000 {
+		t.Errorf("expected a small compressed size, got %d", n)
+	}
+}
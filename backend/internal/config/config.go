@@ -14,6 +14,12 @@ type Config struct {
 	MaxBytes     int64
 	EnablePprof     bool
     PprofPort       string
+	StreamingParser bool
+	// FingerprintRules is an optional path to a technology rules file that
+	// replaces the embedded one.
+	FingerprintRules string
+	// JSVulnDB is the path to a retire.js format vulnerability database.
+	JSVulnDB string
 }
 
 func Load() Config {
@@ -28,6 +34,9 @@ func Load() Config {
 		MaxBytes:     maxBytes,
 		EnablePprof:  getEnv("ENABLE_PPROF", "true") == "true",
         PprofPort:    getEnv("PPROF_PORT", "6060"),
+		StreamingParser: getEnv("PARSER_STREAMING", "false") == "true",
+		FingerprintRules: getEnv("FINGERPRINT_RULES", ""),
+		JSVulnDB:         getEnv("JS_VULN_DB", "data/jsrepository.json"),
 	}
 
 	slog.Info("configuration loaded",
@@ -37,6 +46,9 @@ func Load() Config {
 		"max_bytes", cfg.MaxBytes,
 		"ENABLE_PPROF", cfg.EnablePprof,
 		"PPROF_PORT", cfg.PprofPort,
+		"PARSER_STREAMING", cfg.StreamingParser,
+		"FINGERPRINT_RULES", cfg.FingerprintRules,
+		"JS_VULN_DB", cfg.JSVulnDB,
 	)
 
 	return cfg
@@ -0,0 +1,238 @@
+// Package conformance checks a document against a target doctype. It flags
+// elements and attributes that are obsolete, deprecated or non-standard for
+// that doctype, works out the rendering mode browsers pick from the declared
+// doctype, and suggests how to migrate.
+package conformance
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"golang.org/x/net/html"
+)
+
+// Targets a document can be checked against. TargetDeclared picks the
+// target matching the document's own doctype.
+const (
+	TargetHTML5              = "html5"
+	TargetHTML4Strict        = "html4-strict"
+	TargetHTML4Transitional  = "html4-transitional"
+	TargetHTML4Frameset      = "html4-frameset"
+	TargetXHTML1Strict       = "xhtml1-strict"
+	TargetXHTML1Transitional = "xhtml1-transitional"
+	TargetXHTML1Frameset     = "xhtml1-frameset"
+	TargetDeclared           = "declared"
+)
+
+type target struct {
+	name    string
+	flavour flavour
+	doctype string
+}
+
+var targets = map[string]target{
+	TargetHTML5: {"HTML5", 0, "<!DOCTYPE html>"},
+	TargetHTML4Strict: {"HTML 4.01 Strict", strict,
+		`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`},
+	TargetHTML4Transitional: {"HTML 4.01 Transitional", transitional,
+		`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`},
+	TargetHTML4Frameset: {"HTML 4.01 Frameset", frameset,
+		`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN" "http://www.w3.org/TR/html4/frameset.dtd">`},
+	TargetXHTML1Strict: {"XHTML 1.0 Strict", strict,
+		`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`},
+	TargetXHTML1Transitional: {"XHTML 1.0 Transitional", transitional,
+		`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`},
+	TargetXHTML1Frameset: {"XHTML 1.0 Frameset", frameset,
+		`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Frameset//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-frameset.dtd">`},
+}
+
+// declaredTargets maps parser HTML versions to the closest target.
+// Anything not listed, including HTML5, is checked against HTML5.
+var declaredTargets = map[string]string{
+	"HTML 4.01 Strict":       TargetHTML4Strict,
+	"HTML 4.01":              TargetHTML4Strict,
+	"HTML 4.01 Transitional": TargetHTML4Transitional,
+	"HTML 4.0":               TargetHTML4Transitional,
+	"HTML 3.2":               TargetHTML4Transitional,
+	"HTML 2.0":               TargetHTML4Transitional,
+	"HTML 4.01 Frameset":     TargetHTML4Frameset,
+	"XHTML 1.0 Strict":       TargetXHTML1Strict,
+	"XHTML 1.0":              TargetXHTML1Strict,
+	"XHTML 1.1":              TargetXHTML1Strict,
+	"XHTML 1.0 Transitional": TargetXHTML1Transitional,
+	"XHTML 1.0 Frameset":     TargetXHTML1Frameset,
+}
+
+// ValidTarget reports whether t names a target. The empty string selects
+// TargetHTML5.
+func ValidTarget(t string) bool {
+	_, ok := targets[t]
+	return ok || t == "" || t == TargetDeclared
+}
+
+// Item is an obsolete element, or an obsolete attribute on an element.
+type Item struct {
+	Element string
+	// Attribute is empty when the element itself is obsolete.
+	Attribute string
+	Count     int
+	// Path locates the first occurrence.
+	Path        string
+	Message     string
+	Replacement string
+}
+
+// Report is the conformance of a document with a target doctype.
+type Report struct {
+	Target string
+	// Declared is the HTML version of the document's own doctype.
+	Declared   string
+	Mode       string
+	ModeReason string
+	// Items is empty when the document tree is not available.
+	Items     []Item
+	Migration []string
+}
+
+// Check checks p against targetName, one of the Target constants or empty
+// for HTML5. Obsolete markup is only looked for when p.Doc is set.
+func Check(p *parser.Parsed, targetName string) Report {
+	switch targetName {
+	case "":
+		targetName = TargetHTML5
+	case TargetDeclared:
+		targetName = TargetHTML5
+		if t, ok := declaredTargets[p.HTMLVersion]; ok {
+			targetName = t
+		}
+	}
+	t := targets[targetName]
+
+	rep := Report{Target: targetName, Declared: p.HTMLVersion}
+	rep.Mode, rep.ModeReason = Mode(p.Doctype)
+	if p.Doc != nil {
+		rep.Items = findObsolete(p.Doc.Nodes, t)
+	}
+	rep.Migration = migration(rep, t)
+	return rep
+}
+
+func findObsolete(roots []*html.Node, t target) []Item {
+	byKey := map[[2]string]*Item{}
+	var order []*Item
+	add := func(n *html.Node, tag, attrName string, allowed flavour, replacement string) {
+		if t.flavour != 0 && allowed&t.flavour != 0 {
+			return
+		}
+		key := [2]string{tag, attrName}
+		it := byKey[key]
+		if it == nil {
+			it = &Item{
+				Element:     tag,
+				Attribute:   attrName,
+				Path:        parser.ElementPath(n),
+				Message:     message(tag, attrName, allowed, t),
+				Replacement: replacement,
+			}
+			byKey[key] = it
+			order = append(order, it)
+		}
+		it.Count++
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			// SVG and MathML have their own vocabularies.
+			if n.Namespace != "" {
+				return
+			}
+			tag := strings.ToLower(n.Data)
+			if r, ok := elementRules[tag]; ok {
+				add(n, tag, "", r.allowed, r.replacement)
+			}
+			for _, a := range n.Attr {
+				if a.Namespace != "" {
+					continue
+				}
+				if r, ok := attrRuleFor(tag, strings.ToLower(a.Key)); ok {
+					add(n, tag, r.name, r.allowed, r.replacement)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range roots {
+		walk(n)
+	}
+
+	items := make([]Item, 0, len(order))
+	for _, it := range order {
+		items = append(items, *it)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Count > items[j].Count })
+	return items
+}
+
+func message(tag, attrName string, allowed flavour, t target) string {
+	subject := "<" + tag + ">"
+	if attrName != "" {
+		subject = "the " + attrName + " attribute on <" + tag + ">"
+	}
+	switch {
+	case t.flavour == 0:
+		return subject + " is obsolete in HTML5"
+	case allowed == 0:
+		return subject + " is non-standard"
+	default:
+		return subject + " is not allowed in " + t.name
+	}
+}
+
+// migration lists the steps that bring the document to the target.
+func migration(rep Report, t target) []string {
+	var steps []string
+	same := declaredTargets[rep.Declared] == rep.Target ||
+		(rep.Target == TargetHTML5 && strings.HasPrefix(rep.Declared, "HTML5"))
+	if !same || rep.Mode == ModeQuirks {
+		steps = append(steps, "Replace the doctype with "+t.doctype+".")
+	}
+	if rep.Mode == ModeQuirks {
+		steps = append(steps, "Leaving quirks mode changes the CSS box model, table sizing and line heights; check the layout after switching.")
+	}
+
+	var frames, presentational []string
+	for _, it := range rep.Items {
+		name := "<" + it.Element + ">"
+		if it.Attribute != "" {
+			name = it.Attribute
+		}
+		switch {
+		case it.Attribute == "" && (it.Element == "frameset" || it.Element == "frame" || it.Element == "noframes"):
+			frames = appendUnique(frames, name)
+		case strings.Contains(it.Replacement, "CSS"):
+			presentational = appendUnique(presentational, name)
+		default:
+			steps = append(steps, name+": "+it.Replacement)
+		}
+	}
+	if len(frames) > 0 {
+		steps = append(steps, "Rebuild the "+strings.Join(frames, ", ")+" layout as a single document, embedding other documents with <iframe> where needed.")
+	}
+	if len(presentational) > 0 {
+		steps = append(steps, "Move presentational markup ("+strings.Join(presentational, ", ")+") to a stylesheet.")
+	}
+	return steps
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,136 @@
+package conformance_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/conformance"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestMode(t *testing.T) {
+	cases := []struct {
+		name string
+		d    *parser.Doctype
+		want string
+	}{
+		{"missing", nil, conformance.ModeQuirks},
+		{"html5", &parser.Doctype{Name: "html"}, conformance.ModeStandards},
+		{"legacy-compat", &parser.Doctype{Name: "html", SystemID: "about:legacy-compat"}, conformance.ModeStandards},
+		{"not html", &parser.Doctype{Name: "svg"}, conformance.ModeQuirks},
+		{"html 3.2", &parser.Doctype{Name: "html", PublicID: "-//W3C//DTD HTML 3.2 Final//EN"}, conformance.ModeQuirks},
+		{"4.01 transitional without system id", &parser.Doctype{Name: "html", PublicID: "-//W3C//DTD HTML 4.01 Transitional//EN"}, conformance.ModeQuirks},
+		{"4.01 transitional", &parser.Doctype{
+			Name: "html", PublicID: "-//W3C//DTD HTML 4.01 Transitional//EN", SystemID: "http://www.w3.org/TR/html4/loose.dtd",
+		}, conformance.ModeLimitedQuirks},
+		{"4.01 strict", &parser.Doctype{Name: "html", PublicID: "-//W3C//DTD HTML 4.01//EN"}, conformance.ModeStandards},
+		{"xhtml transitional", &parser.Doctype{Name: "html", PublicID: "-//W3C//DTD XHTML 1.0 Transitional//EN"}, conformance.ModeLimitedQuirks},
+		{"ibm", &parser.Doctype{Name: "html", SystemID: "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd"}, conformance.ModeQuirks},
+	}
+	for _, tc := range cases {
+		if got, reason := conformance.Mode(tc.d); got != tc.want {
+			t.Errorf("%s: expected %s, got %s (%s)", tc.name, tc.want, got, reason)
+		}
+	}
+}
+
+const legacyPage = `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN">
+<html><body bgcolor="#fff">
+  <center><font face="Arial">Welcome</font> <font size="2">again</font></center>
+  <marquee>News</marquee>
+  <table cellpadding="2"><tr><td align="left" bgcolor="red">x</td></tr></table>
+  <p align="center">Text <acronym title="HyperText">HT</acronym></p>
+  <svg><font-face></font-face><text align="x">svg</text></svg>
+</body></html>`
+
+func parse(t *testing.T, page string, stream bool) *parser.Parsed {
+	t.Helper()
+	u, _ := url.Parse("https://example.com/")
+	parse := parser.Parse
+	if stream {
+		parse = parser.ParseStream
+	}
+	p, err := parse(strings.NewReader(page), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func items(rep conformance.Report) map[string]conformance.Item {
+	m := map[string]conformance.Item{}
+	for _, it := range rep.Items {
+		key := it.Element
+		if it.Attribute != "" {
+			key += "@" + it.Attribute
+		}
+		m[key] = it
+	}
+	return m
+}
+
+func TestCheck_HTML5(t *testing.T) {
+	rep := conformance.Check(parse(t, legacyPage, false), "")
+
+	if rep.Target != conformance.TargetHTML5 || rep.Declared != "HTML 4.01 Transitional" || rep.Mode != conformance.ModeQuirks {
+		t.Errorf("unexpected report header %+v", rep)
+	}
+	got := items(rep)
+	for _, key := range []string{"font", "center", "marquee", "acronym", "body@bgcolor", "td@bgcolor", "td@align", "p@align", "table@cellpadding"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("expected %s to be flagged, got %v", key, rep.Items)
+		}
+	}
+	if len(got) != 9 {
+		t.Errorf("expected 9 items, got %v", rep.Items)
+	}
+	if font := got["font"]; font.Count != 2 || font.Path != "html > body > center > font" || font.Message != "<font> is obsolete in HTML5" {
+		t.Errorf("unexpected font item %+v", font)
+	}
+	if rep.Items[0].Element != "font" {
+		t.Errorf("expected the most frequent item first, got %+v", rep.Items[0])
+	}
+	if len(rep.Migration) < 2 || !strings.Contains(rep.Migration[0], "<!DOCTYPE html>") || !strings.Contains(rep.Migration[1], "quirks mode") {
+		t.Errorf("expected the doctype and quirks steps first, got %q", rep.Migration)
+	}
+}
+
+func TestCheck_Targets(t *testing.T) {
+	p := parse(t, legacyPage, false)
+
+	strict := items(conformance.Check(p, conformance.TargetHTML4Strict))
+	for _, key := range []string{"font", "center", "marquee", "body@bgcolor", "p@align"} {
+		if _, ok := strict[key]; !ok {
+			t.Errorf("html4-strict: expected %s to be flagged", key)
+		}
+	}
+	for _, key := range []string{"acronym", "td@align", "table@cellpadding"} {
+		if _, ok := strict[key]; ok {
+			t.Errorf("html4-strict: %s is valid in HTML 4.01 Strict", key)
+		}
+	}
+	if m := strict["marquee"].Message; m != "<marquee> is non-standard" {
+		t.Errorf("unexpected marquee message %q", m)
+	}
+
+	declared := conformance.Check(p, conformance.TargetDeclared)
+	if declared.Target != conformance.TargetHTML4Transitional {
+		t.Errorf("expected the declared doctype to select html4-transitional, got %s", declared.Target)
+	}
+	if got := items(declared); len(got) != 1 || got["marquee"].Count != 1 {
+		t.Errorf("expected only marquee under HTML 4.01 Transitional, got %v", declared.Items)
+	}
+}
+
+func TestCheck_StreamingParser(t *testing.T) {
+	rep := conformance.Check(parse(t, legacyPage, true), conformance.TargetHTML5)
+	if rep.Mode != conformance.ModeQuirks || len(rep.Items) != 0 {
+		t.Errorf("expected the mode without items, got %+v", rep)
+	}
+
+	modern := conformance.Check(parse(t, "<!doctype html><p>Hi</p>", true), "")
+	if modern.Mode != conformance.ModeStandards || len(modern.Migration) != 0 {
+		t.Errorf("expected a conforming HTML5 page, got %+v", modern)
+	}
+}
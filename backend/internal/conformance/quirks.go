@@ -0,0 +1,117 @@
+package conformance
+
+import (
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+// Rendering modes browsers choose from the doctype.
+const (
+	ModeStandards     = "standards"
+	ModeLimitedQuirks = "limited-quirks"
+	ModeQuirks        = "quirks"
+)
+
+// quirkyPublicPrefixes are the public identifiers that put a document in
+// quirks mode, from the HTML standard's "initial" insertion mode.
+var quirkyPublicPrefixes = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
+
+var quirkyPublicIDs = map[string]bool{
+	"-//w3o//dtd w3 html strict 3.0//en//": true,
+	"-/w3c/dtd html 4.0 transitional/en":   true,
+	"html":                                 true,
+}
+
+const quirkySystemID = "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd"
+
+// Mode returns the rendering mode a browser picks for a document with
+// doctype d, which is nil when the document has none, and why.
+func Mode(d *parser.Doctype) (mode, reason string) {
+	if d == nil {
+		return ModeQuirks, "the document has no doctype"
+	}
+	if !strings.EqualFold(strings.TrimSpace(d.Name), "html") {
+		return ModeQuirks, "the doctype name is not html"
+	}
+
+	pub := strings.ToLower(d.PublicID)
+	sys := strings.ToLower(d.SystemID)
+	if quirkyPublicIDs[pub] || sys == quirkySystemID {
+		return ModeQuirks, "the doctype uses a legacy identifier"
+	}
+	for _, p := range quirkyPublicPrefixes {
+		if strings.HasPrefix(pub, p) {
+			return ModeQuirks, "the doctype is a pre-HTML 4.01 or vendor DTD"
+		}
+	}
+
+	html401 := strings.HasPrefix(pub, "-//w3c//dtd html 4.01 frameset//") ||
+		strings.HasPrefix(pub, "-//w3c//dtd html 4.01 transitional//")
+	switch {
+	case html401 && d.SystemID == "":
+		return ModeQuirks, "the HTML 4.01 Transitional or Frameset doctype has no system identifier"
+	case html401:
+		return ModeLimitedQuirks, "the HTML 4.01 Transitional or Frameset doctype triggers almost-standards mode"
+	case strings.HasPrefix(pub, "-//w3c//dtd xhtml 1.0 frameset//"),
+		strings.HasPrefix(pub, "-//w3c//dtd xhtml 1.0 transitional//"):
+		return ModeLimitedQuirks, "the XHTML 1.0 Transitional or Frameset doctype triggers almost-standards mode"
+	}
+	return ModeStandards, ""
+}
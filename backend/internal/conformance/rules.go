@@ -0,0 +1,125 @@
+package conformance
+
+// flavour is a set of HTML 4.01 / XHTML 1.0 DTDs. HTML5 allows none of the
+// markup in the tables below.
+type flavour uint8
+
+const (
+	strict flavour = 1 << iota
+	transitional
+	frameset
+
+	loose = transitional | frameset
+	html4 = strict | transitional | frameset
+)
+
+// elementRule describes an element that is obsolete in HTML5. allowed is
+// zero for elements that were never standardised.
+type elementRule struct {
+	allowed     flavour
+	replacement string
+}
+
+var elementRules = map[string]elementRule{
+	"font":      {loose, "Use CSS font-family, font-size and color."},
+	"basefont":  {loose, "Use CSS font properties on the body."},
+	"center":    {loose, "Use CSS text-align: center, or margin: auto for blocks."},
+	"strike":    {loose, "Use <del> for removed text, or <s> for text that is no longer accurate."},
+	"dir":       {loose, "Use <ul>."},
+	"isindex":   {loose, "Use a <form> with a text <input>."},
+	"applet":    {loose, "Use <object> or <embed>, or rewrite without Java."},
+	"frameset":  {frameset, "Use a single document, or <iframe> for embedded content."},
+	"frame":     {frameset, "Use <iframe>."},
+	"noframes":  {loose, "Remove it; frames are no longer supported."},
+	"acronym":   {html4, "Use <abbr>."},
+	"big":       {html4, "Use CSS font-size."},
+	"tt":        {html4, "Use <code>, <kbd> or <samp>, or CSS font-family: monospace."},
+	"marquee":   {0, "Use CSS animations, and respect prefers-reduced-motion."},
+	"blink":     {0, "Use CSS animations, and respect prefers-reduced-motion."},
+	"bgsound":   {0, "Use <audio>."},
+	"nobr":      {0, "Use CSS white-space: nowrap."},
+	"spacer":    {0, "Use CSS margin or padding."},
+	"multicol":  {0, "Use CSS columns."},
+	"listing":   {0, "Use <pre> with escaped content."},
+	"xmp":       {0, "Use <pre> with escaped content."},
+	"plaintext": {0, "Serve the content as text/plain, or use <pre>."},
+	"keygen":    {0, "Use the Web Crypto API or client certificates."},
+}
+
+// attrRule describes an attribute that is obsolete in HTML5 on the listed
+// elements, or on any element when elements is empty.
+type attrRule struct {
+	name        string
+	elements    []string
+	allowed     flavour
+	replacement string
+}
+
+var tableParts = []string{"col", "colgroup", "tbody", "td", "tfoot", "th", "thead", "tr"}
+
+var attrRules = []attrRule{
+	// align was already deprecated on most elements in HTML 4.01 Strict,
+	// but stayed valid on table parts.
+	{"align", tableParts, html4, "Use CSS text-align."},
+	{"align", nil, loose, "Use CSS text-align, float or vertical-align."},
+	{"valign", tableParts, html4, "Use CSS vertical-align."},
+	{"bgcolor", nil, loose, "Use CSS background-color."},
+	{"background", nil, loose, "Use CSS background-image."},
+	{"text", []string{"body"}, loose, "Use CSS color."},
+	{"link", []string{"body"}, loose, "Use CSS color on a:link."},
+	{"vlink", []string{"body"}, loose, "Use CSS color on a:visited."},
+	{"alink", []string{"body"}, loose, "Use CSS color on a:active."},
+	{"hspace", []string{"img", "object", "applet"}, loose, "Use CSS margin."},
+	{"vspace", []string{"img", "object", "applet"}, loose, "Use CSS margin."},
+	{"border", []string{"img", "object"}, loose, "Use CSS border."},
+	{"clear", []string{"br"}, loose, "Use CSS clear."},
+	{"nowrap", []string{"td", "th"}, loose, "Use CSS white-space: nowrap."},
+	{"width", []string{"td", "th", "hr", "pre"}, loose, "Use CSS width."},
+	{"height", []string{"td", "th"}, loose, "Use CSS height."},
+	{"width", []string{"table", "col", "colgroup"}, html4, "Use CSS width."},
+	{"noshade", []string{"hr"}, loose, "Use CSS border and background-color."},
+	{"size", []string{"hr"}, loose, "Use CSS height."},
+	{"cellpadding", []string{"table"}, html4, "Use CSS padding on the cells."},
+	{"cellspacing", []string{"table"}, html4, "Use CSS border-spacing."},
+	{"frame", []string{"table"}, html4, "Use CSS border."},
+	{"rules", []string{"table"}, html4, "Use CSS border on the cells."},
+	{"summary", []string{"table"}, html4, "Use a <caption> or aria-describedby."},
+	{"compact", []string{"ul", "ol", "dl", "dir", "menu"}, loose, "Use CSS margin and padding."},
+	{"type", []string{"ul", "li"}, loose, "Use CSS list-style-type."},
+	{"language", []string{"script"}, loose, "Remove it; omit type for JavaScript."},
+	{"name", []string{"a"}, html4, "Use an id on the target element."},
+	{"rev", []string{"a", "link"}, html4, "Use rel with the inverse relation."},
+	{"charset", []string{"a", "link"}, html4, "Serve the linked resource with a Content-Type charset."},
+	{"longdesc", []string{"img", "iframe", "frame"}, html4, "Link to the description, or use aria-describedby."},
+	{"version", []string{"html"}, html4, "Remove it."},
+	{"frameborder", []string{"iframe"}, loose, "Use CSS border."},
+	{"marginwidth", []string{"iframe"}, loose, "Use CSS padding in the framed document."},
+	{"marginheight", []string{"iframe"}, loose, "Use CSS padding in the framed document."},
+	{"marginwidth", []string{"body"}, 0, "Use CSS margin."},
+	{"marginheight", []string{"body"}, 0, "Use CSS margin."},
+	{"scrolling", []string{"iframe"}, loose, "Use CSS overflow."},
+}
+
+// attrRulesByName indexes attrRules. The first rule that applies to an
+// element wins, so element-specific rules come before general ones.
+var attrRulesByName = func() map[string][]attrRule {
+	m := map[string][]attrRule{}
+	for _, r := range attrRules {
+		m[r.name] = append(m[r.name], r)
+	}
+	return m
+}()
+
+func attrRuleFor(tag, name string) (attrRule, bool) {
+	for _, r := range attrRulesByName[name] {
+		if len(r.elements) == 0 {
+			return r, true
+		}
+		for _, e := range r.elements {
+			if e == tag {
+				return r, true
+			}
+		}
+	}
+	return attrRule{}, false
+}
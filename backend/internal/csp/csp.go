@@ -0,0 +1,124 @@
+// Package csp inventories what a strict Content Security Policy would block
+// on a page: inline scripts, with the hashes that would allow them, event
+// handler attributes, javascript: URLs, style attributes and eval. It also
+// flags third-party scripts loaded without Subresource Integrity.
+package csp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+var evalRe = regexp.MustCompile(`\beval\s*\(|\bnew\s+Function\s*\(`)
+
+// Script is an executable inline script.
+type Script struct {
+	Type  string
+	Bytes int
+	// Hash is the CSP source expression that allows the script, such as
+	// 'sha256-...'.
+	Hash string
+	// Eval reports a call to eval or new Function.
+	Eval bool
+}
+
+// Report is the inventory for one page.
+type Report struct {
+	Scripts  []Script
+	Handlers []parser.AttrValue
+	JSURLs   []parser.AttrValue
+	Styles   []parser.AttrValue
+	// NoSRI lists the third-party scripts without an integrity attribute.
+	NoSRI []string
+}
+
+// Audit builds the inventory from the parsed page.
+func Audit(scripts []parser.InlineScript, code parser.InlineCode, resources []parser.Resource) Report {
+	rep := Report{Handlers: code.Handlers, JSURLs: code.JSURLs, Styles: code.Styles}
+	for _, s := range scripts {
+		if !executable(s.Type) {
+			continue
+		}
+		rep.Scripts = append(rep.Scripts, Script{
+			Type:  s.Type,
+			Bytes: len(s.Content),
+			Hash:  Hash(s.Content),
+			Eval:  evalRe.MatchString(s.Content),
+		})
+	}
+	for _, r := range resources {
+		if r.Kind == parser.KindScript && r.ThirdParty && !r.Integrity {
+			rep.NoSRI = append(rep.NoSRI, r.URL)
+		}
+	}
+	return rep
+}
+
+// executable reports whether a script type runs, as opposed to data blocks
+// such as JSON or templates, which CSP does not block.
+func executable(typ string) bool {
+	switch typ {
+	case "", "module", "text/javascript", "application/javascript", "application/x-javascript",
+		"text/ecmascript", "application/ecmascript":
+		return true
+	}
+	return false
+}
+
+// Hash returns the CSP hash source for an inline script or style: the
+// base64 SHA-256 of its exact content, quoted as a policy expects.
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// Findings summarizes the report.
+func Findings(rep Report) []contract.Finding {
+	var findings []contract.Finding
+	add := func(code, severity string, n int, msg, example string) {
+		if n == 0 {
+			return
+		}
+		if example != "" {
+			msg += ", e.g. " + example
+		}
+		findings = append(findings, contract.Finding{Code: code, Severity: severity,
+			Message: fmt.Sprintf("%d %s", n, msg), Element: example})
+	}
+	attr := func(list []parser.AttrValue) string {
+		if len(list) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s[%s]", list[0].Tag, list[0].Name)
+	}
+
+	evals := 0
+	for _, s := range rep.Scripts {
+		if s.Eval {
+			evals++
+		}
+	}
+	var noSRI string
+	if len(rep.NoSRI) > 0 {
+		noSRI = rep.NoSRI[0]
+	}
+
+	add("inline_scripts", contract.SeverityWarning, len(rep.Scripts),
+		"inline script(s) need a hash or nonce in script-src; the hashes are listed per script", "")
+	add("inline_event_handlers", contract.SeverityWarning, len(rep.Handlers),
+		"event handler attribute(s) will not run without 'unsafe-inline' or 'unsafe-hashes'", attr(rep.Handlers))
+	add("javascript_urls", contract.SeverityWarning, len(rep.JSURLs),
+		"javascript: URL(s) will not run without 'unsafe-inline'", attr(rep.JSURLs))
+	add("inline_styles", contract.SeverityInfo, len(rep.Styles),
+		"style attribute(s) need 'unsafe-inline' or 'unsafe-hashes' in style-src", attr(rep.Styles))
+	add("eval_usage", contract.SeverityWarning, evals,
+		"inline script(s) call eval or new Function, which needs 'unsafe-eval'", "")
+	add("missing_sri", contract.SeverityWarning, len(rep.NoSRI),
+		"third-party script(s) have no integrity attribute", noSRI)
+	return findings
+}
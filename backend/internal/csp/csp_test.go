@@ -0,0 +1,61 @@
+package csp_test
+
+import (
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/csp"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestHash(t *testing.T) {
+	// The example from the CSP Level 3 specification.
+	if got := csp.Hash("alert('Hello, world.');"); got != "'sha256-qznLcsROx4GACP2dm0UCKCzCG+HiZ1guq6ZZDob/Tng='" {
+		t.Errorf("unexpected hash %s", got)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	scripts := []parser.InlineScript{
+		{Content: "init();"},
+		{Type: "module", Content: "var f = new Function('a', 'return a');"},
+		{Type: "application/ld+json", Content: `{"@type":"Organization"}`},
+	}
+	code := parser.InlineCode{
+		Handlers: []parser.AttrValue{{Tag: "button", Name: "onclick", Value: "go()"}},
+		Styles:   []parser.AttrValue{{Tag: "div", Name: "style", Value: "color:red"}},
+	}
+	resources := []parser.Resource{
+		{Kind: parser.KindScript, URL: "https://cdn.test/a.js", ThirdParty: true},
+		{Kind: parser.KindScript, URL: "https://cdn.test/b.js", ThirdParty: true, Integrity: true},
+		{Kind: parser.KindScript, URL: "https://example.com/c.js"},
+		{Kind: parser.KindStylesheet, URL: "https://cdn.test/a.css", ThirdParty: true},
+	}
+
+	rep := csp.Audit(scripts, code, resources)
+	if len(rep.Scripts) != 2 || rep.Scripts[0].Bytes != 7 || rep.Scripts[0].Eval || !rep.Scripts[1].Eval {
+		t.Errorf("unexpected scripts %+v", rep.Scripts)
+	}
+	if len(rep.NoSRI) != 1 || rep.NoSRI[0] != "https://cdn.test/a.js" {
+		t.Errorf("unexpected scripts without SRI %v", rep.NoSRI)
+	}
+
+	codes := map[string]string{}
+	for _, f := range csp.Findings(rep) {
+		codes[f.Code] = f.Element
+	}
+	want := map[string]string{
+		"inline_scripts":        "",
+		"inline_event_handlers": "button[onclick]",
+		"inline_styles":         "div[style]",
+		"eval_usage":            "",
+		"missing_sri":           "https://cdn.test/a.js",
+	}
+	if len(codes) != len(want) {
+		t.Errorf("expected findings %v, got %v", want, codes)
+	}
+	for code, el := range want {
+		if got, ok := codes[code]; !ok || got != el {
+			t.Errorf("expected %s finding on %q, got %v", code, el, codes)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package fetch
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+)
+
+// Body is the response body returned by Get. Reads return the content,
+// decoded from gzip or deflate and capped at the client's byte limit.
+// Closing it is a no-op; close the response body instead.
+type Body struct {
+	wire     wireReader
+	encoding string
+	dec      io.Reader
+	err      error
+	left     int64
+}
+
+func newBody(r io.Reader, encoding string, maxBytes int64) *Body {
+	return &Body{
+		wire:     wireReader{r: r},
+		encoding: strings.ToLower(strings.TrimSpace(encoding)),
+		left:     maxBytes,
+	}
+}
+
+func (b *Body) Read(p []byte) (int, error) {
+	if b.dec == nil && b.err == nil {
+		b.dec, b.err = b.decoder()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.left <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.dec.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
+
+func (b *Body) Close() error {
+	return nil
+}
+
+// WireBytes is the number of bytes received so far, before decoding.
+func (b *Body) WireBytes() int64 {
+	return b.wire.n
+}
+
+// decoder is created on the first read, because the gzip and zlib readers
+// read their header straight away.
+func (b *Body) decoder() (io.Reader, error) {
+	var dec io.Reader
+	var err error
+	switch b.encoding {
+	case "gzip", "x-gzip":
+		dec, err = gzip.NewReader(&b.wire)
+	case "deflate":
+		dec, err = zlib.NewReader(&b.wire)
+	default:
+		return &b.wire, nil
+	}
+	if err == io.EOF {
+		return strings.NewReader(""), nil
+	}
+	return dec, err
+}
+
+type wireReader struct {
+	r io.Reader
+	n int64
+}
+
+func (w *wireReader) Read(p []byte) (int, error) {
+	n, err := w.r.Read(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -43,6 +43,11 @@ func (c *Client) AllowLocal() {
 	c.allowLocal = true
 }
 
+// MaxBytes is the cap on the decoded size of a response body.
+func (c *Client) MaxBytes() int64 {
+	return c.maxBytes
+}
+
 var ErrPrivateAddr = errors.New("refusing to fetch private address")
 
 func (c *Client) guard(u *url.URL) error {
@@ -63,6 +68,33 @@ func (c *Client) guard(u *url.URL) error {
 	return nil
 }
 
+// Transport returns a round tripper that applies the same address guard as
+// Get to every request, redirects included, so that other HTTP clients
+// checking page links cannot reach private addresses either.
+func (c *Client) Transport() http.RoundTripper {
+	return guardedTransport{c: c}
+}
+
+type guardedTransport struct {
+	c *Client
+}
+
+func (t guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.c.guard(req.URL); err != nil {
+		return nil, err
+	}
+	return t.c.hc.Transport.RoundTrip(req)
+}
+
+// FinalURL returns the URL resp was finally served from, after redirects, or
+// fallback when the response does not record its request.
+func FinalURL(resp *http.Response, fallback *url.URL) *url.URL {
+	if resp.Request != nil && resp.Request.URL != nil {
+		return resp.Request.URL
+	}
+	return fallback
+}
+
 func (c *Client) Get(ctx context.Context, raw string) (*http.Response, io.ReadCloser, error) {
 	u, err := url.Parse(raw)
 	if err != nil {
@@ -79,6 +111,9 @@ func (c *Client) Get(ctx context.Context, raw string) (*http.Response, io.ReadCl
 		return nil, nil, err
 	}
 	req.Header.Set("User-Agent", "GoPageAnalyzer/1.0")
+	// Decoding is done by Body rather than the transport, so that the
+	// Content-Encoding header and the transfer size stay visible.
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
 
 	slog.Debug("fetching URL", "url", raw)
 	resp, err := c.hc.Do(req)
@@ -88,6 +123,5 @@ func (c *Client) Get(ctx context.Context, raw string) (*http.Response, io.ReadCl
 	}
 	slog.Debug("fetch completed", "url", raw, "status", resp.StatusCode)
 
-	lr := &io.LimitedReader{R: resp.Body, N: c.maxBytes}
-	return resp, io.NopCloser(lr), nil
+	return resp, newBody(resp.Body, resp.Header.Get("Content-Encoding"), c.maxBytes), nil
 }
@@ -1,10 +1,13 @@
 package fetch_test
 
 import (
+	"compress/gzip"
 	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,3 +91,52 @@ func TestFetch_RespectsMaxBytes(t *testing.T) {
 		t.Errorf("expected max 1024 bytes, got %d", len(data))
 	}
 }
+
+func TestFetch_DecodesGzip(t *testing.T) {
+	page := strings.Repeat("<p>hello</p>", 200)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte(page))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(page))
+		gz.Close()
+	}))
+	defer ts.Close()
+
+	c := fetch.New(1*time.Second, 3, 1<<20)
+	c.AllowLocal()
+
+	resp, body, err := c.Get(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(body)
+	if string(data) != page {
+		t.Errorf("expected the decoded page, got %d bytes", len(data))
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("expected Content-Encoding to be kept, got %q", resp.Header.Get("Content-Encoding"))
+	}
+	wire := body.(*fetch.Body).WireBytes()
+	if wire == 0 || wire >= int64(len(page)) {
+		t.Errorf("expected a compressed transfer size, got %d for %d bytes", wire, len(page))
+	}
+}
+
+func TestFinalURL(t *testing.T) {
+	fallback, _ := url.Parse("http://example.com/start")
+	final, _ := url.Parse("http://example.com/final")
+
+	if got := fetch.FinalURL(&http.Response{}, fallback); got != fallback {
+		t.Errorf("without a request, got %v, want the fallback", got)
+	}
+	resp := &http.Response{Request: &http.Request{URL: final}}
+	if got := fetch.FinalURL(resp, fallback); got != final {
+		t.Errorf("got %v, want %v", got, final)
+	}
+}
@@ -0,0 +1,365 @@
+// Package fingerprint detects the technologies a page is built with: CMSs,
+// frameworks, analytics, CDNs and servers. Detection is driven by a rules
+// file that matches response headers, cookies, meta tags, script URLs and
+// DOM markers. A default rules file is embedded; Load reads a replacement so
+// the rules can be updated without a rebuild.
+package fingerprint
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/chanaka-withanage/page-analyzer/pkg/extractor"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Name is the section name of the fingerprint extractor.
+const Name = "technologies"
+
+// Technology is a detected technology. Version is empty when none could be
+// inferred; Evidence lists what matched.
+type Technology struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+	Version    string   `json:"version,omitempty"`
+	Evidence   []string `json:"evidence"`
+}
+
+// Rules is a compiled rules file.
+type Rules struct {
+	techs  []*tech
+	byName map[string]*tech
+}
+
+type tech struct {
+	name       string
+	categories []string
+	headers    []pattern
+	meta       []pattern
+	cookies    []pattern
+	scripts    []pattern
+	dom        []domPattern
+	implies    []string
+}
+
+// pattern matches a value, optionally under a key (header, meta or cookie
+// name). A nil re matches any value. The first capture group, if any, is the
+// version; spec ranks it against versions from other patterns.
+type pattern struct {
+	key  string
+	re   *regexp.Regexp
+	spec int
+}
+
+type domPattern struct {
+	selector  string
+	matcher   cascadia.Selector
+	attribute string
+	re        *regexp.Regexp
+	spec      int
+}
+
+// ruleFile is the JSON layout of a rules file. Patterns are regular
+// expressions matched case-insensitively; an empty pattern only checks for
+// presence.
+type ruleFile struct {
+	Technologies map[string]struct {
+		Categories []string          `json:"categories"`
+		Headers    map[string]string `json:"headers"`
+		Meta       map[string]string `json:"meta"`
+		Cookies    map[string]string `json:"cookies"`
+		Scripts    []string          `json:"scripts"`
+		DOM        []struct {
+			Selector  string `json:"selector"`
+			Attribute string `json:"attribute"`
+			Pattern   string `json:"pattern"`
+		} `json:"dom"`
+		Implies []string `json:"implies"`
+	} `json:"technologies"`
+}
+
+// Parse compiles a rules file.
+func Parse(data []byte) (*Rules, error) {
+	var f ruleFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fingerprint rules: %w", err)
+	}
+
+	rules := &Rules{byName: map[string]*tech{}}
+	for name, r := range f.Technologies {
+		t := &tech{name: name, categories: r.Categories, implies: r.Implies}
+		var err error
+		if t.headers, err = compileMap(name, r.Headers, http.CanonicalHeaderKey); err != nil {
+			return nil, err
+		}
+		if t.meta, err = compileMap(name, r.Meta, strings.ToLower); err != nil {
+			return nil, err
+		}
+		if t.cookies, err = compileMap(name, r.Cookies, nil); err != nil {
+			return nil, err
+		}
+		for _, s := range r.Scripts {
+			re, err := compile(name, s)
+			if err != nil {
+				return nil, err
+			}
+			t.scripts = append(t.scripts, pattern{re: re, spec: specificity(s)})
+		}
+		for _, d := range r.DOM {
+			sel, err := cascadia.Compile(d.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("fingerprint rules: %s: selector %q: %w", name, d.Selector, err)
+			}
+			re, err := compile(name, d.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			t.dom = append(t.dom, domPattern{selector: d.Selector, matcher: sel, attribute: d.Attribute, re: re, spec: specificity(d.Pattern)})
+		}
+		rules.techs = append(rules.techs, t)
+		rules.byName[name] = t
+	}
+	for _, t := range rules.techs {
+		for _, imp := range t.implies {
+			if rules.byName[imp] == nil {
+				return nil, fmt.Errorf("fingerprint rules: %s implies unknown technology %q", t.name, imp)
+			}
+		}
+	}
+	sort.Slice(rules.techs, func(i, j int) bool { return rules.techs[i].name < rules.techs[j].name })
+	return rules, nil
+}
+
+// Load reads and compiles the rules file at path.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fingerprint rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Default returns the embedded rules.
+func Default() *Rules {
+	r, err := Parse(defaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func compile(name, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("fingerprint rules: %s: %w", name, err)
+	}
+	return re, nil
+}
+
+// specificity counts the literal characters in a pattern, so that a rule
+// such as `/widget/v([\d.]+)/widget\.js` outranks a bare `([\d.]+)`.
+func specificity(expr string) int {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	var count func(re *syntax.Regexp) int
+	count = func(re *syntax.Regexp) int {
+		n := 0
+		if re.Op == syntax.OpLiteral {
+			n = len(re.Rune)
+		}
+		for _, sub := range re.Sub {
+			n += count(sub)
+		}
+		return n
+	}
+	return count(re)
+}
+
+func compileMap(name string, m map[string]string, canon func(string) string) ([]pattern, error) {
+	var out []pattern
+	for k, expr := range m {
+		re, err := compile(name, expr)
+		if err != nil {
+			return nil, err
+		}
+		if canon != nil {
+			k = canon(k)
+		}
+		out = append(out, pattern{key: k, re: re, spec: specificity(expr)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].key < out[j].key })
+	return out, nil
+}
+
+// Page is what the rules are matched against.
+type Page struct {
+	Header  http.Header
+	Doc     *goquery.Document
+	Cookies []*http.Cookie
+}
+
+// Detect returns the technologies found on the page, ordered by name.
+func (r *Rules) Detect(p Page) []Technology {
+	meta := map[string][]string{}
+	var scripts []string
+	if p.Doc != nil {
+		p.Doc.Find("meta[name][content]").Each(func(_ int, s *goquery.Selection) {
+			name := strings.ToLower(strings.TrimSpace(s.AttrOr("name", "")))
+			meta[name] = append(meta[name], s.AttrOr("content", ""))
+		})
+		p.Doc.Find("script[src]").Each(func(_ int, s *goquery.Selection) {
+			scripts = append(scripts, s.AttrOr("src", ""))
+		})
+	}
+
+	found := map[string]*Technology{}
+	for _, t := range r.techs {
+		var d detection
+		for _, h := range t.headers {
+			for _, v := range p.Header.Values(h.key) {
+				d.match(h.re, h.spec, v, "header "+h.key)
+			}
+		}
+		for _, m := range t.meta {
+			for _, v := range meta[m.key] {
+				d.match(m.re, m.spec, v, "meta "+m.key)
+			}
+		}
+		for _, c := range t.cookies {
+			for _, ck := range p.Cookies {
+				if ck.Name == c.key {
+					d.match(c.re, c.spec, ck.Value, "cookie "+c.key)
+				}
+			}
+		}
+		for _, s := range t.scripts {
+			for _, src := range scripts {
+				d.match(s.re, s.spec, src, "script "+src)
+			}
+		}
+		if p.Doc != nil {
+			for _, dp := range t.dom {
+				p.Doc.FindMatcher(dp.matcher).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+					v := s.AttrOr(dp.attribute, "")
+					if dp.attribute == "" {
+						v = s.Text()
+					}
+					return !d.match(dp.re, dp.spec, v, "dom "+dp.selector)
+				})
+			}
+		}
+		if d.found {
+			found[t.name] = &Technology{Name: t.name, Categories: t.categories, Version: d.version, Evidence: d.evidence}
+		}
+	}
+
+	// Implied technologies, transitively: Next.js implies React.
+	for changed := true; changed; {
+		changed = false
+		for _, tf := range found {
+			for _, imp := range r.byName[tf.Name].implies {
+				if found[imp] == nil {
+					it := r.byName[imp]
+					found[imp] = &Technology{Name: imp, Categories: it.categories, Evidence: []string{"implied by " + tf.Name}}
+					changed = true
+				}
+			}
+		}
+	}
+
+	out := make([]Technology, 0, len(found))
+	for _, t := range found {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// detection accumulates the matches of one technology.
+type detection struct {
+	found    bool
+	version  string
+	spec     int
+	evidence []string
+}
+
+// match tests value against re (nil matches anything) and records the
+// evidence. The version comes from the most specific pattern that yields
+// one; among equally specific patterns, the first match wins.
+func (d *detection) match(re *regexp.Regexp, spec int, value, evidence string) bool {
+	if re == nil {
+		d.record(evidence, "", 0)
+		return true
+	}
+	m := re.FindStringSubmatch(value)
+	if m == nil {
+		return false
+	}
+	version := ""
+	for _, g := range m[1:] {
+		if g != "" {
+			version = g
+			break
+		}
+	}
+	d.record(evidence, version, spec)
+	return true
+}
+
+func (d *detection) record(evidence, version string, spec int) {
+	d.found = true
+	if version != "" && (d.version == "" || spec > d.spec) {
+		d.version, d.spec = version, spec
+	}
+	for _, e := range d.evidence {
+		if e == evidence {
+			return
+		}
+	}
+	if len(d.evidence) < 5 {
+		d.evidence = append(d.evidence, evidence)
+	}
+}
+
+// Extractor reports detected technologies as the "technologies" section.
+type Extractor struct {
+	Rules *Rules
+}
+
+// New returns an extractor using rules.
+func New(rules *Rules) Extractor {
+	return Extractor{Rules: rules}
+}
+
+func (Extractor) Name() string { return Name }
+
+// TreeOptional marks the extractor as usable with the streaming parser:
+// header and cookie rules still apply without a document tree.
+func (Extractor) TreeOptional() {}
+
+func (e Extractor) Extract(_ context.Context, in *extractor.Input) (any, error) {
+	var cookies []*http.Cookie
+	for _, line := range in.Response.Header.Values("Set-Cookie") {
+		if c, err := http.ParseSetCookie(line); err == nil {
+			cookies = append(cookies, c)
+		}
+	}
+	return e.Rules.Detect(Page{Header: in.Response.Header, Doc: in.Doc, Cookies: cookies}), nil
+}
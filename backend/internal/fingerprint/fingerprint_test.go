@@ -0,0 +1,163 @@
+package fingerprint_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chanaka-withanage/page-analyzer/internal/fingerprint"
+	"github.com/chanaka-withanage/page-analyzer/pkg/extractor"
+)
+
+func mustDoc(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+	return doc
+}
+
+func byName(techs []fingerprint.Technology) map[string]fingerprint.Technology {
+	m := map[string]fingerprint.Technology{}
+	for _, t := range techs {
+		m[t.Name] = t
+	}
+	return m
+}
+
+func TestDetect_WordPressOnCloudflare(t *testing.T) {
+	doc := mustDoc(t, `<html><head>
+		<meta name="generator" content="WordPress 6.4.2">
+		<link rel="stylesheet" href="/wp-content/themes/x/style.css">
+		<script src="/wp-includes/js/jquery/jquery.min.js?ver=3.7.1"></script>
+		<script async src="https://www.googletagmanager.com/gtag/js?id=G-ABC123"></script>
+	</head><body></body></html>`)
+	header := http.Header{}
+	header.Set("Server", "cloudflare")
+	header.Set("Cf-Ray", "84a1b2c3d4e5f6a7-AMS")
+
+	found := byName(fingerprint.Default().Detect(fingerprint.Page{
+		Header:  header,
+		Doc:     doc,
+		Cookies: []*http.Cookie{{Name: "_ga", Value: "GA1.1.1"}},
+	}))
+
+	wp, ok := found["WordPress"]
+	if !ok || wp.Version != "6.4.2" {
+		t.Errorf("expected WordPress 6.4.2, got %+v", wp)
+	}
+	if php := found["PHP"]; len(php.Evidence) != 1 || php.Evidence[0] != "implied by WordPress" {
+		t.Errorf("expected PHP implied by WordPress, got %+v", php)
+	}
+	for _, name := range []string{"Cloudflare", "Google Analytics", "Google Tag Manager", "jQuery"} {
+		if _, ok := found[name]; !ok {
+			t.Errorf("expected %s to be detected, got %v", name, found)
+		}
+	}
+	if _, ok := found["Drupal"]; ok {
+		t.Errorf("did not expect Drupal")
+	}
+}
+
+func TestDetect_Frameworks(t *testing.T) {
+	doc := mustDoc(t, `<html><body>
+		<div id="__next"></div>
+		<app-root ng-version="17.1.0"></app-root>
+		<script id="__NEXT_DATA__" type="application/json">{}</script>
+		<script src="https://unpkg.com/vue@3.4.15/dist/vue.global.prod.js"></script>
+	</body></html>`)
+	header := http.Header{}
+	header.Set("X-Powered-By", "Next.js 14.1.0")
+
+	found := byName(fingerprint.Default().Detect(fingerprint.Page{Header: header, Doc: doc}))
+
+	want := map[string]string{"Next.js": "14.1.0", "React": "", "Angular": "17.1.0", "Vue.js": "3.4.15"}
+	for name, version := range want {
+		tech, ok := found[name]
+		if !ok {
+			t.Errorf("expected %s to be detected", name)
+			continue
+		}
+		if tech.Version != version {
+			t.Errorf("expected %s version %q, got %q", name, version, tech.Version)
+		}
+	}
+}
+
+func TestParse_RejectsBadRules(t *testing.T) {
+	cases := map[string]string{
+		"bad regex":     `{"technologies": {"X": {"scripts": ["("]}}}`,
+		"bad selector":  `{"technologies": {"X": {"dom": [{"selector": "[["}]}}}`,
+		"unknown imply": `{"technologies": {"X": {"implies": ["Y"]}}}`,
+		"bad json":      `{`,
+	}
+	for name, data := range cases {
+		if _, err := fingerprint.Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExtractor_ReadsCookiesFromResponse(t *testing.T) {
+	rules, err := fingerprint.Parse([]byte(`{"technologies": {
+		"Shopify": {"categories": ["Ecommerce"], "cookies": {"_shopify_y": ""}}
+	}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	header := http.Header{}
+	header.Add("Set-Cookie", "_shopify_y=abc; Path=/; Secure")
+
+	v, err := fingerprint.New(rules).Extract(context.Background(), &extractor.Input{
+		Doc:      mustDoc(t, `<html></html>`),
+		Response: extractor.Response{StatusCode: 200, Header: header},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	techs := v.([]fingerprint.Technology)
+	if len(techs) != 1 || techs[0].Name != "Shopify" || techs[0].Evidence[0] != "cookie _shopify_y" {
+		t.Errorf("unexpected result %+v", techs)
+	}
+}
+
+func TestDetect_VersionFromMostSpecificRule(t *testing.T) {
+	rules, err := fingerprint.Parse([]byte(`{"technologies": {
+		"Widget": {"headers": {"X-Widget": "([\\d.]+)"}, "scripts": ["/widget/v([\\d.]+)/widget\\.min\\.js"]}
+	}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	header := http.Header{}
+	header.Set("X-Widget", "20240115.2")
+
+	found := byName(rules.Detect(fingerprint.Page{
+		Header: header,
+		Doc:    mustDoc(t, `<script src="/widget/v2.1/widget.min.js"></script>`),
+	}))
+	if w := found["Widget"]; w.Version != "2.1" {
+		t.Errorf("expected the version from the script rule, got %+v", w)
+	}
+}
+
+func TestExtractor_WorksWithoutTree(t *testing.T) {
+	header := http.Header{}
+	header.Set("Server", "nginx/1.25.3")
+
+	e := fingerprint.New(fingerprint.Default())
+	if _, ok := any(e).(extractor.TreeOptional); !ok {
+		t.Fatalf("expected the fingerprint extractor to run without a document tree")
+	}
+	v, err := e.Extract(context.Background(), &extractor.Input{
+		Response: extractor.Response{StatusCode: 200, Header: header},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nginx, ok := byName(v.([]fingerprint.Technology))["Nginx"]; !ok || nginx.Version != "1.25.3" {
+		t.Errorf("expected Nginx 1.25.3 from headers alone, got %+v", v)
+	}
+}
@@ -2,14 +2,20 @@ package gateway
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/chanaka-withanage/page-analyzer/internal/similarity"
 	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
 )
 
+// maxExtractFields bounds the user-defined selectors in one analyze request.
+const maxExtractFields = 50
+
 func (s *server) analyze(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
@@ -20,7 +26,18 @@ func (s *server) analyze(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		URL string `json:"url"`
+		URL               string `json:"url"`
+		CheckHreflang     bool   `json:"check_hreflang"`
+		TopTerms          int    `json:"top_terms"`
+		ScanScripts       bool   `json:"scan_scripts"`
+		ConformanceTarget string `json:"conformance_target"`
+		FollowRefresh     bool   `json:"follow_refresh"`
+		FetchImages       bool   `json:"fetch_images"`
+		CheckAssetCaching bool   `json:"check_asset_caching"`
+		CheckSiteFiles    bool   `json:"check_site_files"`
+		FollowRelations   bool   `json:"follow_relations"`
+
+		Extract map[string]contract.ExtractRule `json:"extract"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		slog.Warn("invalid JSON payload", "err", err)
@@ -28,6 +45,12 @@ func (s *server) analyze(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(body.Extract) > maxExtractFields {
+		slog.Warn("too many extract fields", "fields", len(body.Extract))
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("extract accepts at most %d fields", maxExtractFields))
+		return
+	}
+
 	raw := strings.TrimSpace(body.URL)
     if raw == "" {
     	slog.Warn("missing url field in request")
@@ -45,7 +68,17 @@ func (s *server) analyze(w http.ResponseWriter, r *http.Request) {
     slog.Info("starting analysis", "url", u.String())
 
 	res, err := s.svc.Analyze(r.Context(), contract.AnalyzeParams{
-		URL: u.String(),
+		URL:               u.String(),
+		CheckHreflang:     body.CheckHreflang,
+		TopTerms:          body.TopTerms,
+		ScanScripts:       body.ScanScripts,
+		ConformanceTarget: strings.ToLower(strings.TrimSpace(body.ConformanceTarget)),
+		FollowRefresh:     body.FollowRefresh,
+		FetchImages:       body.FetchImages,
+		CheckAssetCaching: body.CheckAssetCaching,
+		CheckSiteFiles:    body.CheckSiteFiles,
+		FollowRelations:   body.FollowRelations,
+		Extract:           body.Extract,
 	})
 
 	status := http.StatusOK
@@ -69,3 +102,132 @@ func (s *server) analyze(w http.ResponseWriter, r *http.Request) {
 		slog.Error("failed to encode response", "url", u.String(), "err", err)
 	}
 }
+
+func (s *server) content(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	if r.Method != http.MethodPost {
+		slog.Warn("invalid method on /content", "method", r.Method)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var body struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		slog.Warn("invalid JSON payload", "err", err)
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	raw := strings.TrimSpace(body.URL)
+	if raw == "" {
+		slog.Warn("missing url field in request")
+		writeError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+
+	u, err := normalizeAndValidateURL(raw)
+	if err != nil {
+		slog.Warn("url failed validation", "url", raw, "err", err)
+		writeError(w, http.StatusBadRequest, "please provide a valid http(s) URL")
+		return
+	}
+
+	res, err := s.svc.Content(r.Context(), contract.ContentParams{URL: u.String()})
+
+	status := http.StatusOK
+	if err != nil {
+		slog.Error("content extraction failed",
+			"url", u.String(),
+			"err", err,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		slog.Error("failed to encode response", "url", u.String(), "err", err)
+	}
+}
+
+// similarityInput is one side of a /api/similarity request: a URL, or a
+// stored analysis result carrying its content_digest.
+type similarityInput struct {
+	URL    string                  `json:"url"`
+	Result *contract.AnalyzeResult `json:"result"`
+}
+
+func (in similarityInput) params() (contract.SimilarityInput, error) {
+	if in.Result != nil {
+		d := in.Result.ContentDigest
+		if d == nil {
+			return contract.SimilarityInput{}, errors.New("result has no content_digest")
+		}
+		if _, err := similarity.ParseSimHash(d.SimHash); err != nil {
+			return contract.SimilarityInput{}, errors.New("result has an invalid simhash")
+		}
+		return contract.SimilarityInput{URL: in.Result.URL, Digest: d}, nil
+	}
+	if strings.TrimSpace(in.URL) == "" {
+		return contract.SimilarityInput{}, errors.New("a url or a result is required")
+	}
+	u, err := normalizeAndValidateURL(in.URL)
+	if err != nil {
+		return contract.SimilarityInput{}, errors.New("please provide a valid http(s) URL")
+	}
+	return contract.SimilarityInput{URL: u.String()}, nil
+}
+
+func (s *server) similarity(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	if r.Method != http.MethodPost {
+		slog.Warn("invalid method on /similarity", "method", r.Method)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var body struct {
+		A similarityInput `json:"a"`
+		B similarityInput `json:"b"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		slog.Warn("invalid JSON payload", "err", err)
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	a, err := body.A.params()
+	if err != nil {
+		slog.Warn("invalid similarity input", "side", "a", "err", err)
+		writeError(w, http.StatusBadRequest, "a: "+err.Error())
+		return
+	}
+	b, err := body.B.params()
+	if err != nil {
+		slog.Warn("invalid similarity input", "side", "b", "err", err)
+		writeError(w, http.StatusBadRequest, "b: "+err.Error())
+		return
+	}
+
+	res, err := s.svc.Similarity(r.Context(), contract.SimilarityParams{A: a, B: b})
+
+	status := http.StatusOK
+	if err != nil {
+		slog.Error("similarity failed",
+			"a", a.URL,
+			"b", b.URL,
+			"err", err,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		slog.Error("failed to encode response", "err", err)
+	}
+}
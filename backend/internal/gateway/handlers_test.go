@@ -88,3 +88,116 @@ func TestAnalyzeHandler_InvalidJSON(t *testing.T) {
 		t.Errorf("expected 400 Bad Request, got %d", resp.StatusCode)
 	}
 }
+
+func TestContentHandler_ReturnsMarkdown(t *testing.T) {
+	page := startFakePage(`
+		<html>
+		  <head><title>Release Notes | Example</title><meta name="author" content="Docs Team"></head>
+		  <body>
+		    <nav><a href="/">Home</a></nav>
+		    <article>
+		      <h1>Release Notes</h1>
+		      <p>This release adds streaming, caching and a brand new content endpoint for readers.</p>
+		      <img src="/shot.png" alt="Screenshot">
+		    </article>
+		  </body>
+		</html>`)
+	defer page.Close()
+
+	srv := httptest.NewServer(newTestHandler())
+	defer srv.Close()
+
+	reqBody, _ := json.Marshal(map[string]string{"url": page.URL})
+	resp, err := http.Post(srv.URL+"/api/content", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("POST /api/content failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", resp.StatusCode)
+	}
+
+	var result contract.ContentResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if result.Title != "Release Notes | Example" {
+		t.Errorf("unexpected title %q", result.Title)
+	}
+	if result.Byline != "Docs Team" {
+		t.Errorf("unexpected byline %q", result.Byline)
+	}
+	want := "# Release Notes\n\nThis release adds streaming, caching and a brand new content endpoint for readers.\n\n![Screenshot](" + page.URL + "/shot.png)"
+	if result.Markdown != want {
+		t.Errorf("unexpected markdown:\n%s", result.Markdown)
+	}
+	if len(result.Images) != 1 || result.Images[0].Alt != "Screenshot" {
+		t.Errorf("unexpected images %+v", result.Images)
+	}
+}
+
+func TestContentHandler_RejectsInvalidURL(t *testing.T) {
+	srv := httptest.NewServer(newTestHandler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/content", "application/json", bytes.NewReader([]byte(`{"url":"ftp://example.com"}`)))
+	if err != nil {
+		t.Fatalf("POST /api/content failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestSimilarityHandler_ComparesURLAndStoredResult(t *testing.T) {
+	page := startFakePage(`<html><body><p>Stored results can be compared with live pages.</p></body></html>`)
+	defer page.Close()
+
+	srv := httptest.NewServer(newTestHandler())
+	defer srv.Close()
+
+	stored := contract.AnalyzeResult{URL: "https://example.com/old", ContentDigest: &contract.ContentDigest{
+		SHA256:  "not-the-same",
+		SimHash: "0000000000000000",
+		Words:   8,
+	}}
+	reqBody, _ := json.Marshal(map[string]any{
+		"a": map[string]any{"url": page.URL},
+		"b": map[string]any{"result": stored},
+	})
+	resp, err := http.Post(srv.URL+"/api/similarity", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("POST /api/similarity failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", resp.StatusCode)
+	}
+	var result contract.SimilarityResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.A.Digest == nil || result.B.URL != stored.URL || !result.Comparable || result.Identical {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestSimilarityHandler_RequiresBothSides(t *testing.T) {
+	srv := httptest.NewServer(newTestHandler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/similarity", "application/json", bytes.NewReader([]byte(`{"a":{"url":"https://example.com"}}`)))
+	if err != nil {
+		t.Fatalf("POST /api/similarity failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
@@ -18,6 +18,8 @@ func NewMuxWithService(svc *analyzer.Service) http.Handler {
 	s := &server{svc: svc}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/analyze", s.analyze)
+	mux.HandleFunc("/api/content", s.content)
+	mux.HandleFunc("/api/similarity", s.similarity)
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
@@ -0,0 +1,222 @@
+// Package hreflang validates language alternates declared with
+// <link rel="alternate" hreflang> and, optionally, checks them against the
+// alternate pages themselves.
+package hreflang
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// XDefault is the hreflang value for the fallback alternate.
+const XDefault = "x-default"
+
+// bcp47Re matches well-formed BCP 47 (RFC 5646) language tags, excluding
+// the grandfathered irregular forms.
+var bcp47Re = regexp.MustCompile(`(?i)^([a-z]{2,3}(-[a-z]{3}){0,3}|[a-z]{4}|[a-z]{5,8})` +
+	`(-[a-z]{4})?` +
+	`(-([a-z]{2}|[0-9]{3}))?` +
+	`(-([a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` +
+	`(-[0-9a-wy-z](-[a-z0-9]{2,8})+)*` +
+	`(-x(-[a-z0-9]{1,8})+)?$`)
+
+// ValidTag reports whether tag is a well-formed BCP 47 tag or x-default.
+func ValidTag(tag string) bool {
+	return strings.EqualFold(tag, XDefault) || bcp47Re.MatchString(tag)
+}
+
+// Validate checks the declared alternates for well-formed codes, an
+// x-default entry, duplicates and absolute URLs.
+func Validate(alts []parser.Alternate) []contract.Finding {
+	if len(alts) == 0 {
+		return nil
+	}
+
+	var findings []contract.Finding
+	add := func(code, severity, msg string) {
+		findings = append(findings, contract.Finding{Code: code, Severity: severity, Message: msg})
+	}
+
+	seen := map[string]string{}
+	hasDefault := false
+	for _, a := range alts {
+		key := strings.ToLower(a.Hreflang)
+		if key == XDefault {
+			hasDefault = true
+		}
+		if !ValidTag(a.Hreflang) {
+			add("invalid_hreflang", contract.SeverityWarning,
+				fmt.Sprintf("hreflang %q is not a valid BCP 47 language tag", a.Hreflang))
+		}
+		if prev, ok := seen[key]; ok {
+			add("duplicate_hreflang", contract.SeverityWarning,
+				fmt.Sprintf("hreflang %q is declared more than once (%s, %s)", a.Hreflang, prev, a.Href))
+		} else {
+			seen[key] = a.Href
+		}
+		if u, err := url.Parse(a.Href); a.Href == "" || err != nil || !u.IsAbs() {
+			add("relative_hreflang_url", contract.SeverityWarning,
+				fmt.Sprintf("hreflang %q uses a non-absolute URL %q", a.Hreflang, a.Href))
+		}
+	}
+	if !hasDefault {
+		add("missing_x_default", contract.SeverityInfo, "no x-default alternate is declared")
+	}
+	return findings
+}
+
+// Fetcher is the subset of fetch.Client used to load alternate pages.
+type Fetcher interface {
+	Get(ctx context.Context, raw string) (*http.Response, io.ReadCloser, error)
+}
+
+// Result is the outcome of checking one alternate page.
+type Result struct {
+	Hreflang    string
+	URL         string
+	Reciprocal  bool
+	Lang        string
+	LangMatches bool
+	Err         string
+}
+
+// maxChecked bounds how many alternates are fetched for one page.
+const maxChecked = 20
+
+// Check fetches each alternate and reports whether it declares pageURL as
+// one of its own alternates and whether its <html lang> matches the
+// declared hreflang. pageURL should be the URL the page was served from
+// after redirects; alternates are parsed against theirs. At most
+// concurrency alternates are fetched at once.
+func Check(ctx context.Context, f Fetcher, pageURL, pageLang string, alts []parser.Alternate, concurrency int) []Result {
+	var targets []parser.Alternate
+	for _, a := range alts {
+		if a.URL != "" && len(targets) < maxChecked {
+			targets = append(targets, a)
+		}
+	}
+
+	results := make([]Result, len(targets))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, a := range targets {
+		if SameURL(a.URL, pageURL) {
+			results[i] = Result{Hreflang: a.Hreflang, URL: a.URL, Reciprocal: true, Lang: pageLang, LangMatches: LangMatches(a.Hreflang, pageLang)}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, a parser.Alternate) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = Result{Hreflang: a.Hreflang, URL: a.URL, Err: "context cancelled"}
+				return
+			}
+			results[i] = checkOne(ctx, f, pageURL, a)
+		}(i, a)
+	}
+	wg.Wait()
+	return results
+}
+
+func checkOne(ctx context.Context, f Fetcher, pageURL string, a parser.Alternate) Result {
+	r := Result{Hreflang: a.Hreflang, URL: a.URL}
+
+	resp, body, err := f.Get(ctx, a.URL)
+	if err != nil {
+		slog.Warn("hreflang alternate fetch failed", "url", a.URL, "err", err)
+		r.Err = err.Error()
+		return r
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		r.Err = fmt.Sprintf("status %d", resp.StatusCode)
+		return r
+	}
+
+	u, _ := url.Parse(a.URL)
+	u = fetch.FinalURL(resp, u)
+	parsed, err := parser.ParseStream(body, u)
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	r.Lang = parsed.Lang
+	r.LangMatches = LangMatches(a.Hreflang, parsed.Lang)
+	for _, back := range parsed.Alternates {
+		if back.URL != "" && SameURL(back.URL, pageURL) {
+			r.Reciprocal = true
+			break
+		}
+	}
+	return r
+}
+
+// Findings turns check results into findings.
+func Findings(results []Result) []contract.Finding {
+	var findings []contract.Finding
+	for _, r := range results {
+		switch {
+		case r.Err != "":
+			findings = append(findings, contract.Finding{Code: "alternate_unreachable", Severity: contract.SeverityWarning,
+				Message: fmt.Sprintf("alternate %s (%s) could not be checked: %s", r.URL, r.Hreflang, r.Err)})
+			continue
+		case !r.Reciprocal:
+			findings = append(findings, contract.Finding{Code: "alternate_not_reciprocal", Severity: contract.SeverityWarning,
+				Message: fmt.Sprintf("alternate %s (%s) does not link back to this page", r.URL, r.Hreflang)})
+		}
+		if !r.LangMatches {
+			findings = append(findings, contract.Finding{Code: "alternate_lang_mismatch", Severity: contract.SeverityWarning,
+				Message: fmt.Sprintf("alternate %s is declared as %q but its <html lang> is %q", r.URL, r.Hreflang, r.Lang)})
+		}
+	}
+	return findings
+}
+
+// LangMatches reports whether a page declaring lang satisfies hreflang. The
+// primary language subtags must agree; x-default matches anything.
+func LangMatches(hreflang, lang string) bool {
+	if strings.EqualFold(hreflang, XDefault) {
+		return true
+	}
+	return lang != "" && strings.EqualFold(primary(hreflang), primary(lang))
+}
+
+func primary(tag string) string {
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// SameURL compares two absolute URLs, ignoring fragments, scheme and host
+// case, and an empty versus "/" path.
+func SameURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	norm := func(u *url.URL) string {
+		p := u.EscapedPath()
+		if p == "" {
+			p = "/"
+		}
+		return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + p + "?" + u.RawQuery
+	}
+	return norm(ua) == norm(ub)
+}
@@ -0,0 +1,130 @@
+package hreflang_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/hreflang"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestValidTag(t *testing.T) {
+	valid := []string{"en", "en-GB", "de-CH", "zh-Hant-TW", "es-419", "sl-rozaj-biske", "x-default", "en-US-x-twain"}
+	invalid := []string{"", "en_US", "toolonglanguage-gb", "e", "en-", "en--gb", "123"}
+
+	for _, tag := range valid {
+		if !hreflang.ValidTag(tag) {
+			t.Errorf("expected %q to be valid", tag)
+		}
+	}
+	for _, tag := range invalid {
+		if hreflang.ValidTag(tag) {
+			t.Errorf("expected %q to be invalid", tag)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	findings := hreflang.Validate([]parser.Alternate{
+		{Hreflang: "en", Href: "https://example.com/en/"},
+		{Hreflang: "EN", Href: "https://example.com/en-2/"},
+		{Hreflang: "de_DE", Href: "https://example.com/de/"},
+		{Hreflang: "fr", Href: "/fr/"},
+	})
+
+	got := map[string]int{}
+	for _, f := range findings {
+		got[f.Code]++
+	}
+	want := map[string]int{
+		"duplicate_hreflang":    1,
+		"invalid_hreflang":      1,
+		"relative_hreflang_url": 1,
+		"missing_x_default":     1,
+	}
+	for code, n := range want {
+		if got[code] != n {
+			t.Errorf("expected %d %s findings, got %d (%v)", n, code, got[code], findings)
+		}
+	}
+
+	if f := hreflang.Validate(nil); f != nil {
+		t.Errorf("expected no findings without alternates, got %v", f)
+	}
+}
+
+func TestCheck_ReciprocityAndLang(t *testing.T) {
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/de/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html lang="de-DE"><head><link rel="alternate" hreflang="en" href="` + srv.URL + `/en/"></head></html>`))
+	})
+	mux.HandleFunc("/fr/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html lang="en"><head></head></html>`))
+	})
+	mux.HandleFunc("/it/", http.NotFound)
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := fetch.New(5*time.Second, 3, 1<<20)
+	f.AllowLocal()
+
+	page := srv.URL + "/en/"
+	results := hreflang.Check(context.Background(), f, page, "en", []parser.Alternate{
+		{Hreflang: "en", URL: page},
+		{Hreflang: "de", URL: srv.URL + "/de/"},
+		{Hreflang: "fr", URL: srv.URL + "/fr/"},
+		{Hreflang: "it", URL: srv.URL + "/it/"},
+	}, 2)
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if r := results[0]; !r.Reciprocal || !r.LangMatches {
+		t.Errorf("expected self reference to be reciprocal and matching: %+v", r)
+	}
+	if r := results[1]; !r.Reciprocal || !r.LangMatches || r.Lang != "de-DE" {
+		t.Errorf("expected de alternate to be reciprocal and matching: %+v", r)
+	}
+	if r := results[2]; r.Reciprocal || r.LangMatches {
+		t.Errorf("expected fr alternate to be non-reciprocal and mismatched: %+v", r)
+	}
+	if r := results[3]; r.Err == "" {
+		t.Errorf("expected error for missing it alternate: %+v", r)
+	}
+
+	got := map[string]int{}
+	for _, f := range hreflang.Findings(results) {
+		got[f.Code]++
+	}
+	if got["alternate_not_reciprocal"] != 1 || got["alternate_lang_mismatch"] != 1 || got["alternate_unreachable"] != 1 {
+		t.Errorf("unexpected findings: %v", got)
+	}
+}
+
+func TestCheck_FollowsRedirects(t *testing.T) {
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old/fr", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/site/fr/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/site/fr/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html lang="fr"><head><link rel="alternate" hreflang="en" href="../en/"></head></html>`))
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := fetch.New(5*time.Second, 3, 1<<20)
+	f.AllowLocal()
+
+	results := hreflang.Check(context.Background(), f, srv.URL+"/site/en/", "en", []parser.Alternate{
+		{Hreflang: "fr", URL: srv.URL + "/old/fr"},
+	}, 1)
+	if r := results[0]; !r.Reciprocal || !r.LangMatches {
+		t.Errorf("expected relative back link on the redirected alternate to be reciprocal: %+v", r)
+	}
+}
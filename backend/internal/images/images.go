@@ -0,0 +1,245 @@
+// Package images reports image markup that hurts page performance: missing
+// dimensions, eager loading below the fold, large inline data URIs, missing
+// responsive candidates and legacy formats. It can also fetch the images to
+// flag oversized assets.
+package images
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+const (
+	// EagerImages is how many images, in document order, may load eagerly
+	// because they are likely above the fold.
+	EagerImages = 3
+	// MaxDataURIBytes is the largest data URI that is cheaper inline than as
+	// a separate request.
+	MaxDataURIBytes = 10 << 10
+	// LargeWidth is the declared width, in CSS pixels, from which an image
+	// should offer srcset candidates.
+	LargeWidth = 800
+	// OversizedBytes is the transfer size from which an image is flagged.
+	OversizedBytes = 200 << 10
+	// largeBytes is the transfer size from which an image should offer
+	// srcset candidates.
+	largeBytes = 100 << 10
+	// maxFetched bounds how many images are fetched for one page.
+	maxFetched = 30
+)
+
+// legacyFormats are formats with a smaller WebP or AVIF equivalent.
+var legacyFormats = map[string]bool{
+	"jpeg": true,
+	"png":  true,
+	"gif":  true,
+	"bmp":  true,
+	"tiff": true,
+}
+
+// Size is the transfer size of one image.
+type Size struct {
+	URL         string
+	ContentType string
+	// Bytes is -1 when the size is unknown.
+	Bytes int64
+	Err   string
+}
+
+// Check reports the image findings for imgs. sizes holds the fetched sizes,
+// if any, and adds the oversized and missing-srcset checks that need them.
+func Check(imgs []parser.Image, sizes []Size) []contract.Finding {
+	bySrc := map[string]Size{}
+	for _, s := range sizes {
+		if s.Err == "" {
+			bySrc[s.URL] = s
+		}
+	}
+
+	var noDims, notLazy, noSrcset, legacy []string
+	var findings []contract.Finding
+	for i, img := range imgs {
+		name := img.Src
+		if img.URL != "" {
+			name = img.URL
+		}
+		if img.Width == "" || img.Height == "" {
+			noDims = append(noDims, name)
+		}
+		if i >= EagerImages && img.Loading != "lazy" {
+			notLazy = append(notLazy, name)
+		}
+		if img.DataBytes > MaxDataURIBytes {
+			findings = append(findings, contract.Finding{Code: "large_data_uri", Severity: contract.SeverityWarning,
+				Message: fmt.Sprintf("inline %s data URI is %d KiB; serve it as a cacheable file", img.Src, img.DataBytes>>10),
+				Element: img.Src})
+		}
+
+		size, fetched := bySrc[img.URL]
+		if !img.Srcset && (declaredWidth(img) >= LargeWidth || fetched && size.Bytes >= largeBytes) {
+			noSrcset = append(noSrcset, name)
+		}
+		format := Format(img.Src)
+		if fetched && size.ContentType != "" {
+			format = contentTypeFormat(size.ContentType)
+		}
+		if legacyFormats[format] && !img.ModernSource {
+			legacy = append(legacy, name)
+		}
+	}
+
+	summarize := func(code, severity string, list []string, what string) {
+		if len(list) == 0 {
+			return
+		}
+		findings = append(findings, contract.Finding{Code: code, Severity: severity,
+			Message: fmt.Sprintf("%d image(s) %s, e.g. %s", len(list), what, list[0]),
+			Element: list[0]})
+	}
+	summarize("missing_dimensions", contract.SeverityWarning, noDims,
+		"have no width and height attributes, which lets the layout shift as they load")
+	summarize("not_lazy", contract.SeverityInfo, notLazy,
+		fmt.Sprintf("after the first %d load eagerly; add loading=\"lazy\"", EagerImages))
+	summarize("missing_srcset", contract.SeverityInfo, noSrcset,
+		"are large but offer no srcset candidates for smaller screens")
+	summarize("legacy_format", contract.SeverityInfo, legacy,
+		"use a legacy format without a WebP or AVIF alternative")
+
+	for _, s := range sizes {
+		if s.Err == "" && s.Bytes >= OversizedBytes {
+			findings = append(findings, contract.Finding{Code: "oversized_image", Severity: contract.SeverityWarning,
+				Message: fmt.Sprintf("image %s is %d KiB", s.URL, s.Bytes>>10),
+				Element: s.URL})
+		}
+	}
+	return findings
+}
+
+// declaredWidth returns the width attribute in CSS pixels, or 0.
+func declaredWidth(img parser.Image) int {
+	w, err := strconv.Atoi(strings.TrimSuffix(img.Width, "px"))
+	if err != nil {
+		return 0
+	}
+	return w
+}
+
+// Format returns the image format implied by src: the media type of a data
+// URI, or the file extension of a URL. It is empty when unknown.
+func Format(src string) string {
+	if len(src) >= 5 && strings.EqualFold(src[:5], "data:") {
+		mt, _, _ := strings.Cut(src[5:], ",")
+		mt, _, _ = strings.Cut(mt, ";")
+		return contentTypeFormat(mt)
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return ""
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(path.Ext(u.Path), ".")); ext {
+	case "jpg", "jpe", "jfif":
+		return "jpeg"
+	case "tif":
+		return "tiff"
+	default:
+		return ext
+	}
+}
+
+func contentTypeFormat(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil || !strings.HasPrefix(mt, "image/") {
+		return ""
+	}
+	switch f := strings.TrimPrefix(mt, "image/"); f {
+	case "jpg", "pjpeg":
+		return "jpeg"
+	case "x-ms-bmp":
+		return "bmp"
+	case "svg+xml":
+		return "svg"
+	default:
+		return f
+	}
+}
+
+// Fetcher fetches a URL. fetch.Client satisfies it, so image requests go
+// through the same SSRF guard as the page itself.
+type Fetcher interface {
+	Get(ctx context.Context, raw string) (*http.Response, io.ReadCloser, error)
+}
+
+// URLs returns the distinct image URLs worth fetching, in document order.
+func URLs(imgs []parser.Image) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, img := range imgs {
+		if img.URL == "" || seen[img.URL] || len(out) == maxFetched {
+			continue
+		}
+		seen[img.URL] = true
+		out = append(out, img.URL)
+	}
+	return out
+}
+
+// FetchSizes requests each URL, at most concurrency at a time, and reads
+// the size and type from the response headers. Bodies are only read when
+// the response has no Content-Length, up to the fetcher's size limit.
+func FetchSizes(ctx context.Context, f Fetcher, urls []string, concurrency int) []Size {
+	sizes := make([]Size, len(urls))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				sizes[i] = Size{URL: u, Bytes: -1, Err: "context cancelled"}
+				return
+			}
+			sizes[i] = fetchSize(ctx, f, u)
+		}(i, u)
+	}
+	wg.Wait()
+	return sizes
+}
+
+func fetchSize(ctx context.Context, f Fetcher, u string) Size {
+	s := Size{URL: u, Bytes: -1}
+	resp, body, err := f.Get(ctx, u)
+	if err != nil {
+		slog.Warn("image fetch failed", "url", u, "err", err)
+		s.Err = err.Error()
+		return s
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		s.Err = fmt.Sprintf("status %d", resp.StatusCode)
+		return s
+	}
+	s.ContentType = resp.Header.Get("Content-Type")
+	s.Bytes = resp.ContentLength
+	if s.Bytes < 0 {
+		// Chunked responses carry no length; count the body instead.
+		if n, err := io.Copy(io.Discard, body); err == nil {
+			s.Bytes = n
+		}
+	}
+	return s
+}
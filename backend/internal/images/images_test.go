@@ -0,0 +1,126 @@
+package images_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/images"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestFormat(t *testing.T) {
+	cases := map[string]string{
+		"/a/photo.JPG?v=2":             "jpeg",
+		"https://cdn.example/x.webp":   "webp",
+		"scan.tif":                     "tiff",
+		"data:image/png;base64,AAAA":   "png",
+		"data:image/svg+xml,%3Csvg%3E": "svg",
+		"/resize?w=200":                "",
+		"data:text/plain;base64,aGk=":  "",
+	}
+	for src, want := range cases {
+		if got := images.Format(src); got != want {
+			t.Errorf("Format(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	imgs := []parser.Image{
+		{Src: "/hero.jpg", URL: "https://x.test/hero.jpg", Width: "1200", Height: "600"},
+		{Src: "/logo.svg", URL: "https://x.test/logo.svg", Width: "40", Height: "40"},
+		{Src: "/p.png", URL: "https://x.test/p.png", Width: "300", Height: "200", ModernSource: true},
+		{Src: "/below.webp", URL: "https://x.test/below.webp"},
+		{Src: "/lazy.webp", URL: "https://x.test/lazy.webp", Width: "1", Height: "1", Loading: "lazy"},
+		{Src: "data:image/png;base64,", DataBytes: 20 << 10, Width: "1", Height: "1", Loading: "lazy"},
+	}
+
+	got := map[string]string{}
+	for _, f := range images.Check(imgs, nil) {
+		got[f.Code] = f.Element
+	}
+	want := map[string]string{
+		"missing_dimensions": "https://x.test/below.webp",
+		"not_lazy":           "https://x.test/below.webp",
+		"missing_srcset":     "https://x.test/hero.jpg",
+		"legacy_format":      "https://x.test/hero.jpg",
+		"large_data_uri":     "data:image/png;base64,",
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected findings %v, got %v", want, got)
+	}
+	for code, el := range want {
+		if got[code] != el {
+			t.Errorf("expected %s on %q, got %q", code, el, got[code])
+		}
+	}
+}
+
+func TestCheck_FetchedSizes(t *testing.T) {
+	imgs := []parser.Image{
+		{Src: "/a.jpg", URL: "https://x.test/a.jpg", Width: "300", Height: "200"},
+		{Src: "/b", URL: "https://x.test/b", Width: "300", Height: "200"},
+	}
+	sizes := []images.Size{
+		{URL: "https://x.test/a.jpg", ContentType: "image/webp", Bytes: 300 << 10},
+		{URL: "https://x.test/b", ContentType: "image/png", Bytes: 1 << 10},
+	}
+
+	got := map[string][]string{}
+	for _, f := range images.Check(imgs, sizes) {
+		got[f.Code] = append(got[f.Code], f.Element)
+	}
+	// The response type wins over the extension.
+	if l := got["legacy_format"]; len(l) != 1 || l[0] != "https://x.test/b" {
+		t.Errorf("expected only b to be a legacy format, got %v", l)
+	}
+	if l := got["oversized_image"]; len(l) != 1 || l[0] != "https://x.test/a.jpg" {
+		t.Errorf("expected a.jpg to be oversized, got %v", l)
+	}
+	if l := got["missing_srcset"]; len(l) != 1 || l[0] != "https://x.test/a.jpg" {
+		t.Errorf("expected a.jpg to need srcset, got %v", l)
+	}
+}
+
+func TestFetchSizes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+	}))
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 2, 1<<20)
+	f.AllowLocal()
+	sizes := images.FetchSizes(context.Background(), f, []string{ts.URL + "/a.png", ts.URL + "/missing.png"}, 2)
+
+	if len(sizes) != 2 {
+		t.Fatalf("expected 2 sizes, got %+v", sizes)
+	}
+	if s := sizes[0]; s.Err != "" || s.Bytes != 2048 || s.ContentType != "image/png" {
+		t.Errorf("unexpected size for a.png: %+v", s)
+	}
+	if s := sizes[1]; s.Err != "status 404" || s.Bytes != -1 {
+		t.Errorf("expected a 404 for missing.png, got %+v", s)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	got := images.URLs([]parser.Image{
+		{Src: "/a.png", URL: "https://x.test/a.png"},
+		{Src: "data:image/png;base64,", DataBytes: 30},
+		{Src: "/a.png", URL: "https://x.test/a.png"},
+		{Src: "/b.png", URL: "https://x.test/b.png"},
+	})
+	if len(got) != 2 || got[0] != "https://x.test/a.png" || got[1] != "https://x.test/b.png" {
+		t.Errorf("expected distinct URLs in order, got %v", got)
+	}
+}
@@ -0,0 +1,220 @@
+// Package jsvuln identifies front-end JavaScript libraries and their
+// versions, and matches them against a vulnerability database in the
+// retire.js repository format
+// (https://github.com/RetireJS/retire.js/blob/master/repository/jsrepository.json).
+//
+// The database is read from disk so it works offline and can be updated
+// without a rebuild. Libraries are recognised from script URLs, file names
+// and banner comments in script content; retire.js "func" and "hashes"
+// extractors need a JavaScript runtime or the exact file and are ignored.
+package jsvuln
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionPattern replaces the §§version§§ placeholder of retire.js
+// extractors. Each dotted segment starts with a digit, so ".min" and
+// ".slim" suffixes are not taken for part of the version.
+const versionPattern = `[0-9][0-9a-z_\-]*(?:\.[0-9][0-9a-z_\-]*)*`
+
+// DB is a compiled vulnerability database.
+type DB struct {
+	libs []*library
+}
+
+type library struct {
+	name            string
+	uri             []*regexp.Regexp
+	filename        []*regexp.Regexp
+	filecontent     []*regexp.Regexp
+	vulnerabilities []Vulnerability
+}
+
+// Vulnerability is one advisory for a range of library versions.
+type Vulnerability struct {
+	AtOrAbove string
+	Below     string
+	Severity  string
+	CVEs      []string
+	Summary   string
+	Info      []string
+}
+
+type repoEntry struct {
+	Vulnerabilities []struct {
+		AtOrAbove   string `json:"atOrAbove"`
+		Below       string `json:"below"`
+		Severity    string `json:"severity"`
+		Identifiers struct {
+			CVE     []string `json:"CVE"`
+			Summary string   `json:"summary"`
+		} `json:"identifiers"`
+		Info []string `json:"info"`
+	} `json:"vulnerabilities"`
+	Extractors struct {
+		URI         []string `json:"uri"`
+		Filename    []string `json:"filename"`
+		Filecontent []string `json:"filecontent"`
+	} `json:"extractors"`
+}
+
+// Parse compiles a retire.js repository. Extractor patterns that Go's
+// regexp package cannot compile (retire.js uses JavaScript syntax) are
+// skipped rather than failing the whole database.
+func Parse(data []byte) (*DB, error) {
+	var repo map[string]json.RawMessage
+	if err := json.Unmarshal(data, &repo); err != nil {
+		return nil, fmt.Errorf("js vulnerability db: %w", err)
+	}
+
+	db := &DB{}
+	for name, raw := range repo {
+		var e repoEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			// Non-library keys such as "retire-example" metadata.
+			continue
+		}
+		lib := &library{
+			name:        name,
+			uri:         compileAll(e.Extractors.URI),
+			filename:    compileAll(e.Extractors.Filename),
+			filecontent: compileAll(e.Extractors.Filecontent),
+		}
+		for _, v := range e.Vulnerabilities {
+			lib.vulnerabilities = append(lib.vulnerabilities, Vulnerability{
+				AtOrAbove: v.AtOrAbove,
+				Below:     v.Below,
+				Severity:  strings.ToLower(v.Severity),
+				CVEs:      v.Identifiers.CVE,
+				Summary:   v.Identifiers.Summary,
+				Info:      v.Info,
+			})
+		}
+		if len(lib.uri)+len(lib.filename)+len(lib.filecontent) > 0 {
+			db.libs = append(db.libs, lib)
+		}
+	}
+	if len(db.libs) == 0 {
+		return nil, fmt.Errorf("js vulnerability db: no usable library entries")
+	}
+	sort.Slice(db.libs, func(i, j int) bool { return db.libs[i].name < db.libs[j].name })
+	return db, nil
+}
+
+// Load reads and compiles the database at path.
+func Load(path string) (*DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("js vulnerability db: %w", err)
+	}
+	return Parse(data)
+}
+
+// Len returns the number of libraries in the database.
+func (db *DB) Len() int {
+	return len(db.libs)
+}
+
+func compileAll(patterns []string) []*regexp.Regexp {
+	var out []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(strings.ReplaceAll(p, "§§version§§", versionPattern))
+		if err != nil || re.NumSubexp() == 0 {
+			continue
+		}
+		out = append(out, re)
+	}
+	return out
+}
+
+// vulnerable returns the advisories that apply to version.
+func (l *library) vulnerable(version string) []Vulnerability {
+	var out []Vulnerability
+	for _, v := range l.vulnerabilities {
+		if v.Below != "" && CompareVersions(version, v.Below) >= 0 {
+			continue
+		}
+		if v.AtOrAbove != "" && CompareVersions(version, v.AtOrAbove) < 0 {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// CompareVersions compares two dotted versions the way retire.js does:
+// numeric segments numerically, others as strings. When one version is a
+// prefix of the other, an extra numeric segment makes a version newer and
+// an extra pre-release segment ("beta1") makes it older.
+func CompareVersions(a, b string) int {
+	as, bs := splitVersion(a), splitVersion(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareSegment(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(as) > len(bs):
+		return extraSegment(as[len(bs)])
+	case len(bs) > len(as):
+		return -extraSegment(bs[len(as)])
+	}
+	return 0
+}
+
+func splitVersion(v string) []string {
+	return strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+}
+
+func compareSegment(a, b string) int {
+	an, aerr := strconv.Atoi(a)
+	bn, berr := strconv.Atoi(b)
+	switch {
+	case aerr == nil && berr == nil:
+		return cmpInt(an, bn)
+	case aerr == nil:
+		return 1
+	case berr == nil:
+		return -1
+	}
+	// Mixed segments such as "0b1": compare the leading number first.
+	if c := cmpInt(leadingInt(a), leadingInt(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func extraSegment(s string) int {
+	if _, err := strconv.Atoi(s); err == nil {
+		return 1
+	}
+	return -1
+}
+
+func leadingInt(s string) int {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package jsvuln_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/jsvuln"
+)
+
+func loadDB(t *testing.T) *jsvuln.DB {
+	t.Helper()
+	db, err := jsvuln.Load("../../data/jsrepository.json")
+	if err != nil {
+		t.Fatalf("failed to load shipped database: %v", err)
+	}
+	return db
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.10", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"1.9.0", "1.9.0b1", 1},
+		{"1.9.0b1", "1.9.0b2", -1},
+		{"3.0.0-beta1", "3.0.0", -1},
+		{"3.0.0.1", "3.0.0", 1},
+		{"2", "10", -1},
+	}
+	for _, c := range cases {
+		if got := jsvuln.CompareVersions(c.a, c.b); got != c.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestScan_DetectsAndMatchesVulnerabilities(t *testing.T) {
+	db := loadDB(t)
+
+	libs := db.Scan([]jsvuln.Script{
+		{URL: "https://code.jquery.com/jquery-3.4.1.min.js"},
+		{URL: "https://cdnjs.cloudflare.com/ajax/libs/lodash.js/4.17.21/lodash.min.js"},
+		{URL: "https://cdn.example.com/vendor.js", Content: "/*!\n  * Bootstrap v4.3.0 (https://getbootstrap.com/)\n  */"},
+		{Content: "/*! jQuery v3.4.1 | (c) JS Foundation */"},
+		{URL: "https://cdn.example.com/app.js"},
+	})
+
+	got := map[string]jsvuln.Library{}
+	for _, l := range libs {
+		got[l.Name] = l
+	}
+	if len(libs) != 3 {
+		t.Fatalf("expected jquery, lodash and bootstrap once each, got %+v", libs)
+	}
+
+	jq := got["jquery"]
+	if jq.Version != "3.4.1" || jq.Detection != jsvuln.ByFilename {
+		t.Errorf("unexpected jquery detection %+v", jq)
+	}
+	cves := map[string]bool{}
+	for _, v := range jq.Vulnerabilities {
+		for _, c := range v.CVEs {
+			cves[c] = true
+		}
+	}
+	if !cves["CVE-2020-11022"] || !cves["CVE-2020-11023"] || cves["CVE-2019-11358"] {
+		t.Errorf("unexpected jquery 3.4.1 CVEs %v", cves)
+	}
+
+	if l := got["lodash"]; l.Version != "4.17.21" || l.Detection != jsvuln.ByURI || len(l.Vulnerabilities) != 0 {
+		t.Errorf("expected lodash 4.17.21 by uri without vulnerabilities, got %+v", l)
+	}
+	if b := got["bootstrap"]; b.Version != "4.3.0" || b.Detection != jsvuln.ByFilecontent || len(b.Vulnerabilities) != 1 {
+		t.Errorf("expected bootstrap 4.3.0 from its banner with one advisory, got %+v", b)
+	}
+}
+
+func TestParse_SkipsUnusableEntries(t *testing.T) {
+	db, err := jsvuln.Parse([]byte(`{
+		"broken": {"extractors": {"uri": ["(?<=lookbehind)(§§version§§)"]}},
+		"nover": {"extractors": {"filename": ["no-group\\.js"]}},
+		"ok": {"vulnerabilities": [{"below": "2.0.0", "severity": "High", "identifiers": {"summary": "bad"}}],
+		       "extractors": {"filename": ["ok-(§§version§§)\\.js"]}}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.Len() != 1 {
+		t.Errorf("expected only the usable library, got %d", db.Len())
+	}
+	libs := db.Scan([]jsvuln.Script{{URL: "https://x.test/ok-1.2.0-min.js"}})
+	if len(libs) != 1 || libs[0].Version != "1.2.0" || libs[0].Vulnerabilities[0].Severity != "high" {
+		t.Errorf("unexpected scan result %+v", libs)
+	}
+
+	if _, err := jsvuln.Parse([]byte(`{}`)); err == nil {
+		t.Error("expected an error for an empty database")
+	}
+}
+
+func TestFetchContent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.js" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("/*! jQuery v1.12.4 */" + strings.Repeat(";", 1<<20)))
+	}))
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 3, 4<<20)
+	f.AllowLocal()
+
+	content := jsvuln.FetchContent(context.Background(), f, []string{ts.URL + "/lib.js", ts.URL + "/missing.js"}, 2)
+
+	if _, ok := content[ts.URL+"/missing.js"]; ok {
+		t.Error("expected failed downloads to be left out")
+	}
+	body := content[ts.URL+"/lib.js"]
+	if !strings.HasPrefix(body, "/*! jQuery v1.12.4 */") || len(body) >= 1<<20 {
+		t.Errorf("expected a truncated script body, got %d bytes", len(body))
+	}
+}
@@ -0,0 +1,161 @@
+package jsvuln
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxScanBytes bounds how much of a script's content is searched for
+// banner comments.
+const maxScanBytes = 256 << 10
+
+// Detection methods.
+const (
+	ByURI         = "uri"
+	ByFilename    = "filename"
+	ByFilecontent = "filecontent"
+)
+
+// Script is a script on the page: an external one by URL, optionally with
+// the start of its content, or an inline one by content only.
+type Script struct {
+	URL     string
+	Content string
+}
+
+// Library is an identified library version and the advisories that apply
+// to it.
+type Library struct {
+	Name    string
+	Version string
+	// Detection is how the version was found: uri, filename or filecontent.
+	Detection string
+	// Script is the script URL, or empty for an inline script.
+	Script          string
+	Vulnerabilities []Vulnerability
+}
+
+// Scan identifies the libraries in scripts. A library found in several
+// places is reported once per version.
+func (db *DB) Scan(scripts []Script) []Library {
+	var out []Library
+	seen := map[string]bool{}
+	for _, s := range scripts {
+		for _, lib := range db.identify(s) {
+			key := lib.Name + "@" + lib.Version
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, lib)
+		}
+	}
+	return out
+}
+
+func (db *DB) identify(s Script) []Library {
+	var filename string
+	if u, err := url.Parse(s.URL); err == nil && s.URL != "" {
+		filename = path.Base(u.Path)
+	}
+	content := s.Content
+	if len(content) > maxScanBytes {
+		content = content[:maxScanBytes]
+	}
+
+	var out []Library
+	for _, l := range db.libs {
+		version, how := "", ""
+		switch {
+		case s.URL != "" && matchVersion(l.uri, s.URL, &version):
+			how = ByURI
+		case filename != "" && matchVersion(l.filename, filename, &version):
+			how = ByFilename
+		case content != "" && matchVersion(l.filecontent, content, &version):
+			how = ByFilecontent
+		default:
+			continue
+		}
+		out = append(out, Library{
+			Name:            l.name,
+			Version:         version,
+			Detection:       how,
+			Script:          s.URL,
+			Vulnerabilities: l.vulnerable(version),
+		})
+	}
+	return out
+}
+
+func matchVersion(res []*regexp.Regexp, s string, version *string) bool {
+	for _, re := range res {
+		if m := re.FindStringSubmatch(s); m != nil && m[1] != "" {
+			*version = cleanVersion(m[1])
+			return true
+		}
+	}
+	return false
+}
+
+// cleanVersion drops minification suffixes that the version pattern lets
+// through, e.g. "3.5.1-min".
+func cleanVersion(v string) string {
+	for _, suffix := range []string{"-min", "_min", "-slim", "-prod", "-production"} {
+		v = strings.TrimSuffix(v, suffix)
+	}
+	return v
+}
+
+// Fetcher fetches a URL. fetch.Client satisfies it, so script downloads go
+// through the same SSRF guard and size limits as the page itself.
+type Fetcher interface {
+	Get(ctx context.Context, raw string) (*http.Response, io.ReadCloser, error)
+}
+
+// FetchContent downloads the start of each script, at most concurrency at
+// a time, and returns the content by URL. Scripts that fail to download are
+// left out.
+func FetchContent(ctx context.Context, f Fetcher, urls []string, concurrency int) map[string]string {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	out := make(map[string]string, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			resp, body, err := f.Get(ctx, u)
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				return
+			}
+			data, err := io.ReadAll(io.LimitReader(body, maxScanBytes))
+			if err != nil && len(data) == 0 {
+				return
+			}
+			mu.Lock()
+			out[u] = string(data)
+			mu.Unlock()
+		}(u)
+	}
+	wg.Wait()
+	return out
+}
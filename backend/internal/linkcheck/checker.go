@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -13,7 +15,9 @@ type Result struct {
 	URL        string
 	Accessible bool
 	StatusCode int
-	Err        string
+	// Bytes is the Content-Length of the response, or -1 when unknown.
+	Bytes int64
+	Err   string
 }
 
 type Checker struct {
@@ -34,9 +38,90 @@ func New(globalConcurrency, perHostLimit int, timeout time.Duration) *Checker {
 	}
 }
 
+// WithTransport sets the transport used for link requests, such as the
+// guarded one from fetch.Client, and returns the checker.
+func (c *Checker) WithTransport(rt http.RoundTripper) *Checker {
+	c.client.Transport = rt
+	return c
+}
+
 func (c *Checker) Validate(ctx context.Context, links []*url.URL) []Result {
 	results := make([]Result, len(links))
+	c.each(ctx, links, func(ctx context.Context, i int, u *url.URL) {
+		if ctx.Err() != nil {
+			results[i] = Result{URL: u.String(), Bytes: -1, Err: "context cancelled"}
+			return
+		}
+
+		slog.Debug("validating link", "url", u.String())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			slog.Error("link validation failed", "url", u.String(), "err", err)
+			results[i] = Result{URL: u.String(), Accessible: false, Bytes: -1, Err: err.Error()}
+			return
+		}
+		defer resp.Body.Close()
+
+		ok := resp.StatusCode >= 200 && resp.StatusCode < 400
+		results[i] = Result{URL: u.String(), Accessible: ok, StatusCode: resp.StatusCode, Bytes: resp.ContentLength}
+		slog.Debug("link validated", "url", u.String(), "status", resp.StatusCode, "ok", ok)
+	})
+	return results
+}
+
+// Sizes asks for the first byte of each link with a ranged GET and returns
+// the full size from Content-Range, or from Content-Length when the server
+// ignores the range. Sizes are -1 when unknown. Bodies are not read.
+func (c *Checker) Sizes(ctx context.Context, links []*url.URL) []int64 {
+	sizes := make([]int64, len(links))
+	c.each(ctx, links, func(ctx context.Context, i int, u *url.URL) {
+		sizes[i] = -1
+		if ctx.Err() != nil {
+			return
+		}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		req.Header.Set("Range", "bytes=0-0")
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			slog.Warn("ranged size request failed", "url", u.String(), "err", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			sizes[i] = rangeTotal(resp.Header.Get("Content-Range"))
+		case http.StatusOK:
+			sizes[i] = resp.ContentLength
+		}
+	})
+	return sizes
+}
+
+// acceptEncoding is sent so that sizes reflect what browsers transfer.
+const acceptEncoding = "gzip, deflate, br"
 
+// rangeTotal returns the complete length from a Content-Range header such
+// as "bytes 0-0/1234", or -1.
+func rangeTotal(v string) int64 {
+	_, total, ok := strings.Cut(v, "/")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// each calls fn for every link, within the global and per-host limits. fn
+// gets a context bounded by the checker timeout, already cancelled when a
+// limit could not be acquired.
+func (c *Checker) each(ctx context.Context, links []*url.URL, fn func(ctx context.Context, i int, u *url.URL)) {
 	globalSem := make(chan struct{}, c.globalConcurrency)
 	hostSems := sync.Map{}
 
@@ -52,7 +137,7 @@ func (c *Checker) Validate(ctx context.Context, links []*url.URL) []Result {
 				defer func() { <-globalSem }()
 			case <-ctx.Done():
 				slog.Warn("link validation cancelled (global limit)", "url", u.String())
-				results[i] = Result{URL: u.String(), Err: "context cancelled"}
+				fn(ctx, i, u)
 				return
 			}
 
@@ -65,28 +150,14 @@ func (c *Checker) Validate(ctx context.Context, links []*url.URL) []Result {
 				defer func() { <-hostSem }()
 			case <-ctx.Done():
 				slog.Warn("link validation cancelled (per-host limit)", "url", u.String(), "host", h)
-				results[i] = Result{URL: u.String(), Err: "context cancelled"}
+				fn(ctx, i, u)
 				return
 			}
 
 			reqCtx, cancel := context.WithTimeout(ctx, c.timeout)
 			defer cancel()
-
-			slog.Debug("validating link", "url", u.String())
-			req, _ := http.NewRequestWithContext(reqCtx, http.MethodHead, u.String(), nil)
-			resp, err := c.client.Do(req)
-			if err != nil {
-				slog.Error("link validation failed", "url", u.String(), "err", err)
-				results[i] = Result{URL: u.String(), Accessible: false, Err: err.Error()}
-				return
-			}
-			defer resp.Body.Close()
-
-			ok := resp.StatusCode >= 200 && resp.StatusCode < 400
-			results[i] = Result{URL: u.String(), Accessible: ok, StatusCode: resp.StatusCode}
-			slog.Debug("link validated", "url", u.String(), "status", resp.StatusCode, "ok", ok)
+			fn(reqCtx, i, u)
 		}(i, link)
 	}
 	wg.Wait()
-	return results
 }
@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
 	"github.com/chanaka-withanage/page-analyzer/internal/linkcheck"
 )
 
@@ -106,3 +108,92 @@ func TestValidate_Timeout(t *testing.T) {
 		t.Errorf("expected inaccessible due to timeout, got accessible")
 	}
 }
+
+func TestValidate_ReportsContentLength(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1234")
+	}))
+	defer ts.Close()
+
+	checker := linkcheck.New(5, 2, 1*time.Second)
+	results := checker.Validate(context.Background(), []*url.URL{mustURL(ts.URL)})
+
+	if results[0].Bytes != 1234 {
+		t.Errorf("expected 1234 bytes, got %d", results[0].Bytes)
+	}
+}
+
+func TestSizes(t *testing.T) {
+	body := strings.Repeat("x", 5000)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ranged":
+			http.ServeContent(w, r, "a.js", time.Time{}, strings.NewReader(body))
+		case "/full":
+			w.Header().Set("Content-Length", "5000")
+			_, _ = w.Write([]byte(body))
+		case "/chunked":
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(body))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	checker := linkcheck.New(5, 2, 1*time.Second)
+	sizes := checker.Sizes(context.Background(), []*url.URL{
+		mustURL(ts.URL + "/ranged"),
+		mustURL(ts.URL + "/full"),
+		mustURL(ts.URL + "/chunked"),
+		mustURL(ts.URL + "/missing"),
+	})
+
+	want := []int64{5000, 5000, -1, -1}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Errorf("size %d: expected %d, got %d", i, want[i], sizes[i])
+		}
+	}
+}
+
+func TestValidate_GuardedTransportRefusesLoopback(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 3, 1<<20)
+	results := linkcheck.New(5, 2, 1*time.Second).WithTransport(f.Transport()).
+		Validate(context.Background(), []*url.URL{mustURL(ts.URL + "/app.js")})
+	if results[0].Accessible || !strings.Contains(results[0].Err, fetch.ErrPrivateAddr.Error()) {
+		t.Errorf("expected loopback subresource to be refused, got %+v", results[0])
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("expected no request to reach the server, got %d", hits)
+	}
+
+	f.AllowLocal()
+	results = linkcheck.New(5, 2, 1*time.Second).WithTransport(f.Transport()).
+		Validate(context.Background(), []*url.URL{mustURL(ts.URL + "/app.js")})
+	if !results[0].Accessible {
+		t.Errorf("expected loopback to be allowed with AllowLocal, got %+v", results[0])
+	}
+}
+
+func TestSizes_GuardedTransportRefusesLoopback(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Length", "5000")
+	}))
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 3, 1<<20)
+	sizes := linkcheck.New(5, 2, 1*time.Second).WithTransport(f.Transport()).
+		Sizes(context.Background(), []*url.URL{mustURL(ts.URL + "/hero.jpg")})
+	if sizes[0] != -1 || atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("expected loopback size request to be refused, got size %d and %d hits", sizes[0], hits)
+	}
+}
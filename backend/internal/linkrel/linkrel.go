@@ -0,0 +1,176 @@
+// Package linkrel checks the page relationships declared with <link rel>:
+// that AMP pages and their canonical counterparts point at each other, and
+// that rel=next/prev pagination chains are consistent.
+package linkrel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/hreflang"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// maxHops bounds how many pages are followed in each pagination direction.
+const maxHops = 5
+
+// Validate checks the relations the page declares without fetching
+// anything.
+func Validate(pageURL string, amp bool, rel parser.Relations) []contract.Finding {
+	var findings []contract.Finding
+	add := func(code, severity, msg string) {
+		findings = append(findings, contract.Finding{Code: code, Severity: severity, Message: msg})
+	}
+
+	if amp && rel.Canonical == "" {
+		add("amp_missing_canonical", contract.SeverityWarning,
+			"AMP pages must declare a canonical URL, even when it is their own")
+	}
+	if !amp && rel.AMPHTML != "" && hreflang.SameURL(rel.AMPHTML, pageURL) {
+		add("amphtml_self", contract.SeverityWarning,
+			"rel=amphtml points at this page, which is not an AMP document")
+	}
+	if rel.Next != "" && hreflang.SameURL(rel.Next, pageURL) {
+		add("pagination_self", contract.SeverityWarning, "rel=next points at this page")
+	}
+	if rel.Prev != "" && hreflang.SameURL(rel.Prev, pageURL) {
+		add("pagination_self", contract.SeverityWarning, "rel=prev points at this page")
+	}
+	if rel.Next != "" && rel.Prev != "" && hreflang.SameURL(rel.Next, rel.Prev) {
+		add("pagination_loop", contract.SeverityWarning,
+			fmt.Sprintf("rel=next and rel=prev both point at %s", rel.Next))
+	}
+	return findings
+}
+
+// Fetcher is the subset of fetch.Client used to load related pages.
+type Fetcher interface {
+	Get(ctx context.Context, raw string) (*http.Response, io.ReadCloser, error)
+}
+
+// Result is the outcome of following the relations of a page. The chains
+// list the pages reached through rel=next and rel=prev, in order.
+type Result struct {
+	NextChain []string
+	PrevChain []string
+	Findings  []contract.Finding
+}
+
+// page is a related page as loaded by load.
+type page struct {
+	// url is where the page was served from, after redirects.
+	url string
+	amp bool
+	rel parser.Relations
+}
+
+// Check follows the AMP counterpart of the page, or the canonical page of
+// an AMP page, and the pagination chain in both directions, and reports
+// where the pages disagree. pageURL should be the URL the page was served
+// from after redirects; related pages are parsed and judged at theirs.
+func Check(ctx context.Context, f Fetcher, pageURL string, amp bool, rel parser.Relations) Result {
+	var res Result
+	add := func(code, msg string) {
+		res.Findings = append(res.Findings, contract.Finding{Code: code, Severity: contract.SeverityWarning, Message: msg})
+	}
+
+	switch {
+	case !amp && rel.AMPHTML != "" && !hreflang.SameURL(rel.AMPHTML, pageURL):
+		p, err := load(ctx, f, rel.AMPHTML)
+		switch {
+		case err != nil:
+			add("amphtml_unreachable", fmt.Sprintf("AMP page %s could not be checked: %v", rel.AMPHTML, err))
+		case !p.amp:
+			add("amphtml_not_amp", fmt.Sprintf("rel=amphtml target %s is not an AMP document", rel.AMPHTML))
+		case p.rel.Canonical == "" || !hreflang.SameURL(p.rel.Canonical, pageURL) &&
+			!(rel.Canonical != "" && hreflang.SameURL(p.rel.Canonical, rel.Canonical)):
+			add("amp_canonical_mismatch", fmt.Sprintf("AMP page %s declares canonical %q instead of this page", rel.AMPHTML, p.rel.Canonical))
+		}
+	case amp && rel.Canonical != "" && !hreflang.SameURL(rel.Canonical, pageURL):
+		p, err := load(ctx, f, rel.Canonical)
+		switch {
+		case err != nil:
+			add("canonical_unreachable", fmt.Sprintf("canonical page %s could not be checked: %v", rel.Canonical, err))
+		case p.rel.AMPHTML == "" || !hreflang.SameURL(p.rel.AMPHTML, pageURL):
+			add("canonical_amphtml_mismatch", fmt.Sprintf("canonical page %s links to AMP page %q instead of this one", rel.Canonical, p.rel.AMPHTML))
+		}
+	}
+
+	var findings []contract.Finding
+	res.NextChain, findings = chain(ctx, f, pageURL, rel.Next, true)
+	res.Findings = append(res.Findings, findings...)
+	res.PrevChain, findings = chain(ctx, f, pageURL, rel.Prev, false)
+	res.Findings = append(res.Findings, findings...)
+	return res
+}
+
+// chain follows rel=next (forward) or rel=prev links from the page, up to
+// maxHops pages, and checks that each page links back to the one before.
+func chain(ctx context.Context, f Fetcher, pageURL, first string, forward bool) ([]string, []contract.Finding) {
+	dir, back := "prev", "next"
+	if forward {
+		dir, back = "next", "prev"
+	}
+
+	var out []string
+	var findings []contract.Finding
+	add := func(code, msg string) {
+		findings = append(findings, contract.Finding{Code: code, Severity: contract.SeverityWarning, Message: msg})
+	}
+	visited := []string{pageURL}
+	from, cur := pageURL, first
+	for len(out) < maxHops && cur != "" {
+		for _, v := range visited {
+			if hreflang.SameURL(v, cur) {
+				add("pagination_loop", fmt.Sprintf("following rel=%s from %s returns to %s", dir, from, cur))
+				return out, findings
+			}
+		}
+		visited = append(visited, cur)
+		out = append(out, cur)
+
+		p, err := load(ctx, f, cur)
+		if err != nil {
+			add("pagination_unreachable", fmt.Sprintf("rel=%s page %s could not be checked: %v", dir, cur, err))
+			break
+		}
+		backLink, onward := p.rel.Prev, p.rel.Next
+		if !forward {
+			backLink, onward = p.rel.Next, p.rel.Prev
+		}
+		if backLink == "" || !hreflang.SameURL(backLink, from) {
+			add("pagination_not_reciprocal", fmt.Sprintf("%s is rel=%s of %s, but its rel=%s is %q", cur, dir, from, back, backLink))
+		}
+		// The next back link must point where cur actually lives.
+		if p.url != "" && !hreflang.SameURL(p.url, cur) {
+			visited = append(visited, p.url)
+		}
+		from, cur = p.url, onward
+	}
+	return out, findings
+}
+
+func load(ctx context.Context, f Fetcher, raw string) (page, error) {
+	resp, body, err := f.Get(ctx, raw)
+	if err != nil {
+		slog.Warn("related page fetch failed", "url", raw, "err", err)
+		return page{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return page{}, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	u, _ := url.Parse(raw)
+	u = fetch.FinalURL(resp, u)
+	parsed, err := parser.ParseStream(body, u)
+	if err != nil {
+		return page{}, err
+	}
+	return page{url: u.String(), amp: parsed.AMP, rel: parsed.Relations}, nil
+}
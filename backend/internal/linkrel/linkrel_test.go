@@ -0,0 +1,102 @@
+package linkrel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/linkrel"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestValidate(t *testing.T) {
+	page := "https://example.com/list?page=2"
+	got := linkrel.Validate(page, true, parser.Relations{
+		Next: "https://example.com/list?page=2",
+		Prev: "https://example.com/list?page=2",
+	})
+	want := []string{"amp_missing_canonical", "pagination_self", "pagination_self", "pagination_loop"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, got)
+	}
+	for i, f := range got {
+		if f.Code != want[i] {
+			t.Errorf("finding %d: expected %s, got %+v", i, want[i], f)
+		}
+	}
+
+	if got := linkrel.Validate(page, false, parser.Relations{AMPHTML: "https://example.com/amp"}); len(got) != 0 {
+		t.Errorf("expected no findings, got %+v", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	pages := map[string]string{
+		"/amp": `<html amp><head><link rel="canonical" href="/elsewhere"></head></html>`,
+		"/p/2": `<html><head><link rel="prev" href="/p/1"><link rel="next" href="/p/3"></head></html>`,
+		"/p/3": `<html><head><link rel="prev" href="/p/2"><link rel="next" href="/p/1"></head></html>`,
+		"/p/0": `<html><head><link rel="next" href="/p/9"></head></html>`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(html))
+	}))
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 2, 1<<20)
+	f.AllowLocal()
+	res := linkrel.Check(context.Background(), f, ts.URL+"/p/1", false, parser.Relations{
+		AMPHTML: ts.URL + "/amp",
+		Next:    ts.URL + "/p/2",
+		Prev:    ts.URL + "/p/0",
+	})
+
+	if strings.Join(res.NextChain, " ") != ts.URL+"/p/2 "+ts.URL+"/p/3" {
+		t.Errorf("unexpected next chain %v", res.NextChain)
+	}
+	if len(res.PrevChain) != 1 || res.PrevChain[0] != ts.URL+"/p/0" {
+		t.Errorf("unexpected prev chain %v", res.PrevChain)
+	}
+	var got []string
+	for _, fd := range res.Findings {
+		got = append(got, fd.Code)
+	}
+	want := "amp_canonical_mismatch pagination_loop pagination_not_reciprocal"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected findings %s, got %+v", want, res.Findings)
+	}
+}
+
+func TestCheck_FollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old/2", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/list/2", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/list/2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><link rel="prev" href="1"><link rel="next" href="3"></head></html>`))
+	})
+	mux.HandleFunc("/list/3", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><link rel="prev" href="2"></head></html>`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 2, 1<<20)
+	f.AllowLocal()
+	res := linkrel.Check(context.Background(), f, ts.URL+"/list/1", false, parser.Relations{Next: ts.URL + "/old/2"})
+
+	if strings.Join(res.NextChain, " ") != ts.URL+"/old/2 "+ts.URL+"/list/3" {
+		t.Errorf("unexpected next chain %v", res.NextChain)
+	}
+	if len(res.Findings) != 0 {
+		t.Errorf("expected links resolved on the redirected page to be reciprocal, got %+v", res.Findings)
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Alternate is a <link rel="alternate" hreflang> language alternate.
+type Alternate struct {
+	Hreflang string
+	// Href is the attribute as written; URL is Href resolved against the
+	// document base, or empty when it cannot be resolved.
+	Href string
+	URL  string
+}
+
+// alternateRef extracts a language alternate from a <link> element.
+func alternateRef(attrs []html.Attribute) (Alternate, bool) {
+	rel, _ := attr(attrs, "rel")
+	lang, hasLang := attr(attrs, "hreflang")
+	if !hasLang || !hasToken(rel, "alternate") {
+		return Alternate{}, false
+	}
+	href, _ := attr(attrs, "href")
+	return Alternate{Hreflang: strings.TrimSpace(lang), Href: strings.TrimSpace(href)}, true
+}
+
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveAlternates(alts []Alternate, base *url.URL) []Alternate {
+	for i := range alts {
+		if alts[i].Href == "" {
+			continue
+		}
+		if u, err := base.Parse(alts[i].Href); err == nil && u.Host != "" {
+			alts[i].URL = u.String()
+		}
+	}
+	return alts
+}
+
+func collectAlternates(doc *goquery.Document, base *url.URL) []Alternate {
+	var alts []Alternate
+	doc.Find("link[hreflang]").Each(func(_ int, s *goquery.Selection) {
+		if a, ok := alternateRef(s.Nodes[0].Attr); ok {
+			alts = append(alts, a)
+		}
+	})
+	return resolveAlternates(alts, base)
+}
+
+// documentLang returns the lang attribute of the root <html> element.
+func documentLang(doc *goquery.Document) string {
+	lang, _ := doc.Find("html").First().Attr("lang")
+	return strings.TrimSpace(lang)
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxAttrValues caps the collected attribute values.
+const maxAttrValues = 10000
+
+// AttrValue is a text-bearing attribute, such as a mailto: link or an alt
+// text, with the element it was found on. Index numbers the elements with
+// the same tag in document order, from 1, so that Tag and Index locate it.
+type AttrValue struct {
+	Tag   string
+	Index int
+	Name  string
+	Value string
+}
+
+// textAttrs are the attributes whose values are free text or contact links.
+// data-* attributes are included as well.
+var textAttrs = map[string]bool{
+	"href": true, "content": true, "value": true, "title": true, "alt": true,
+	"placeholder": true, "aria-label": true, "label": true,
+}
+
+func appendAttrValues(out []AttrValue, tag string, index int, attrs []html.Attribute) []AttrValue {
+	for _, a := range attrs {
+		if len(out) == maxAttrValues {
+			break
+		}
+		name := strings.ToLower(a.Key)
+		if a.Namespace != "" || strings.TrimSpace(a.Val) == "" || !textAttrs[name] && !strings.HasPrefix(name, "data-") {
+			continue
+		}
+		out = append(out, AttrValue{Tag: tag, Index: index, Name: name, Value: a.Val})
+	}
+	return out
+}
+
+func collectAttrValues(root *html.Node) []AttrValue {
+	var out []AttrValue
+	counts := map[string]int{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			tag := strings.ToLower(n.Data)
+			counts[tag]++
+			out = appendAttrValues(out, tag, counts[tag], n.Attr)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return out
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// documentBase returns the document base URL following the HTML rules: the
+// href of the first <base> element that has one, resolved against the
+// document URL. It falls back to docURL when there is no such element or
+// its value cannot be used.
+func documentBase(root *html.Node, docURL *url.URL) *url.URL {
+	var href string
+	var found bool
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if found {
+			return
+		}
+		if n.Type == html.ElementNode && strings.EqualFold(n.Data, "base") {
+			if v, ok := attr(n.Attr, "href"); ok {
+				href, found = v, true
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil && !found; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	if !found {
+		return docURL
+	}
+	return resolveBase(docURL, href)
+}
+
+// resolveBase resolves a <base href> value against the document URL.
+func resolveBase(docURL *url.URL, href string) *url.URL {
+	u, err := docURL.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return docURL
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "data", "javascript":
+		return docURL
+	}
+	return u
+}
+
+// attr returns the value of the named attribute.
+func attr(attrs []html.Attribute, key string) (string, bool) {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// headElements may appear in <head>; any other start tag, or text that is
+// not whitespace, begins the body.
+var headElements = map[string]bool{
+	"base": true, "basefont": true, "bgsound": true, "link": true, "meta": true,
+	"noframes": true, "noscript": true, "script": true, "style": true,
+	"template": true, "title": true,
+}
+
+// headRawText are the head elements whose content is text, not markup.
+var headRawText = map[string]bool{
+	"noframes": true, "noscript": true, "script": true, "style": true, "title": true,
+}
+
+// classicScriptTypes are the type attribute values of classic scripts,
+// which block the parser unless async or deferred. Module scripts are
+// deferred by default.
+var classicScriptTypes = map[string]bool{
+	"": true, "text/javascript": true, "application/javascript": true,
+	"application/x-javascript": true, "text/ecmascript": true, "application/ecmascript": true,
+}
+
+// blockingRef returns the subresource an element loads in a way that blocks
+// rendering: a synchronous external script in <head>, or a stylesheet that
+// applies to every medium.
+func blockingRef(tag string, attrs []html.Attribute, inHead bool) (resourceRef, bool) {
+	get := func(key string) string {
+		v, _ := attr(attrs, key)
+		return strings.TrimSpace(v)
+	}
+	has := func(key string) bool {
+		_, ok := attr(attrs, key)
+		return ok
+	}
+
+	switch tag {
+	case "script":
+		src := get("src")
+		if !inHead || src == "" || has("async") || has("defer") || !classicScriptTypes[strings.ToLower(get("type"))] {
+			return resourceRef{}, false
+		}
+		return resourceRef{kind: KindScript, raw: src, integrity: get("integrity") != ""}, true
+	case "link":
+		rel, href := get("rel"), get("href")
+		if href == "" || !hasToken(rel, "stylesheet") || hasToken(rel, "alternate") || has("disabled") {
+			return resourceRef{}, false
+		}
+		switch strings.ToLower(get("media")) {
+		case "", "all", "screen":
+			return resourceRef{kind: KindStylesheet, raw: href, integrity: get("integrity") != ""}, true
+		}
+	}
+	return resourceRef{}, false
+}
+
+func collectRenderBlocking(root *html.Node, base, docURL *url.URL) []Resource {
+	set := newResourceSet(base, docURL)
+	var walk func(n *html.Node, inHead bool)
+	walk = func(n *html.Node, inHead bool) {
+		if n.Type == html.ElementNode && n.Namespace == "" {
+			tag := strings.ToLower(n.Data)
+			if ref, ok := blockingRef(tag, n.Attr, inHead); ok {
+				set.add(tag, []resourceRef{ref})
+			}
+			if tag == "head" {
+				inHead = true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inHead)
+		}
+	}
+	walk(root, false)
+	return set.list
+}
@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Content is the main content of an article-like page.
+type Content struct {
+	Title    string
+	Byline   string
+	Markdown string
+	Images   []ContentImage
+}
+
+// ContentImage is an image inside the main content.
+type ContentImage struct {
+	URL string
+	Alt string
+}
+
+var (
+	// unlikelyRe marks page furniture that is skipped while scoring, unless
+	// maybeRe also matches.
+	unlikelyRe = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|consent|cookie|disqus|extra|footer|gdpr|header|menu|newsletter|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|ad-break|agegate|pagination|pager|popup`)
+	maybeRe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	negativeRe = regexp.MustCompile(`(?i)-ad-|hidden|banner|combx|comment|com-|contact|foot|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+
+	bylineRe = regexp.MustCompile(`(?i)byline|author|dateline|writtenby`)
+
+	titleSeparators = []string{" | ", " - ", " – ", " — ", " :: ", " » "}
+)
+
+// boilerplateTags never hold the main content.
+var boilerplateTags = map[string]bool{
+	"nav": true, "aside": true, "footer": true, "header": true, "form": true,
+	"button": true, "dialog": true, "select": true,
+}
+
+// ExtractContent finds the main content of doc the way reader modes do:
+// paragraphs are scored by length and punctuation, the scores flow to their
+// ancestors, and the best-scoring container, together with related
+// siblings, is rendered as Markdown. URLs are resolved against base.
+func ExtractContent(doc *goquery.Document, base *url.URL) Content {
+	c := Content{
+		Title:  contentTitle(doc),
+		Byline: contentByline(doc),
+	}
+
+	md := newMarkdownWriter(base)
+	var blocks []string
+	for _, n := range mainContent(doc) {
+		if s := md.block(n); s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	c.Markdown = strings.Join(blocks, "\n\n")
+	c.Images = md.images
+	return c
+}
+
+// mainContent returns the top-scoring container and the siblings that
+// belong with it, in document order.
+func mainContent(doc *goquery.Document) []*html.Node {
+	scores := map[*html.Node]float64{}
+	var order []*html.Node
+	score := func(n *html.Node, v float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			order = append(order, n)
+		}
+		scores[n] += v
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			tag := strings.ToLower(n.Data)
+			if hiddenElement(tag, n.Attr) || boilerplateTags[tag] || unlikely(n) {
+				return
+			}
+			if isParagraph(n) {
+				text := visibleText(n)
+				if len(text) >= 25 {
+					v := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+					score(n.Parent, v)
+					if n.Parent != nil {
+						score(n.Parent.Parent, v/2)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range doc.Nodes {
+		walk(n)
+	}
+
+	var top *html.Node
+	best := 0.0
+	for _, n := range order {
+		scores[n] *= 1 - linkDensity(n)
+		if scores[n] > best {
+			top, best = n, scores[n]
+		}
+	}
+	if top == nil {
+		if body := doc.Find("body").Nodes; len(body) > 0 {
+			return []*html.Node{body[0]}
+		}
+		return nil
+	}
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := math.Max(10, best*0.2)
+	var nodes []*html.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s.Type != html.ElementNode {
+			continue
+		}
+		if s == top {
+			nodes = append(nodes, s)
+			continue
+		}
+		if v, ok := scores[s]; ok && v >= threshold {
+			nodes = append(nodes, s)
+			continue
+		}
+		if strings.EqualFold(s.Data, "p") {
+			text := visibleText(s)
+			density := linkDensity(s)
+			if (len(text) > 80 && density < 0.25) || (len(text) > 0 && density == 0 && strings.Contains(text, ". ")) {
+				nodes = append(nodes, s)
+			}
+		}
+	}
+	return nodes
+}
+
+// isParagraph reports whether n is scored as a paragraph: p, pre and td
+// elements, and divs that only hold inline content.
+func isParagraph(n *html.Node) bool {
+	switch strings.ToLower(n.Data) {
+	case "p", "pre", "td":
+		return true
+	case "div":
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && !inlineTags[strings.ToLower(c.Data)] && c.Data != "br" && c.Data != "img" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func unlikely(n *html.Node) bool {
+	switch strings.ToLower(n.Data) {
+	case "html", "body", "article", "main":
+		return false
+	}
+	cls, _ := attr(n.Attr, "class")
+	id, _ := attr(n.Attr, "id")
+	s := cls + " " + id
+	return unlikelyRe.MatchString(s) && !maybeRe.MatchString(s)
+}
+
+func initialScore(n *html.Node) float64 {
+	var v float64
+	switch strings.ToLower(n.Data) {
+	case "article":
+		v = 10
+	case "div", "main":
+		v = 5
+	case "pre", "td", "blockquote":
+		v = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		v = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		v = -5
+	}
+	for _, k := range []string{"class", "id"} {
+		s, _ := attr(n.Attr, k)
+		if s == "" {
+			continue
+		}
+		if negativeRe.MatchString(s) {
+			v -= 25
+		}
+		if positiveRe.MatchString(s) {
+			v += 25
+		}
+	}
+	return v
+}
+
+// linkDensity is the share of the text of n that sits inside links.
+func linkDensity(n *html.Node) float64 {
+	text := len(visibleText(n))
+	if text == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.ElementNode && strings.EqualFold(c.Data, "a") {
+			links += len(visibleText(c))
+			return
+		}
+		for cc := c.FirstChild; cc != nil; cc = cc.NextSibling {
+			walk(cc)
+		}
+	}
+	walk(n)
+	return math.Min(float64(links)/float64(text), 1)
+}
+
+// contentTitle prefers og:title over <title>, and drops a trailing site
+// name such as "Article | Example News".
+func contentTitle(doc *goquery.Document) string {
+	title := strings.TrimSpace(doc.Find(`meta[property="og:title"]`).AttrOr("content", ""))
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+	title = strings.Join(strings.Fields(title), " ")
+	for _, sep := range titleSeparators {
+		if i := strings.LastIndex(title, sep); i > 0 {
+			if head := title[:i]; len(strings.Fields(head)) >= 3 {
+				return head
+			}
+		}
+	}
+	return title
+}
+
+func contentByline(doc *goquery.Document) string {
+	if v := doc.Find(`meta[name="author"], meta[property="article:author"]`).AttrOr("content", ""); strings.TrimSpace(v) != "" && !strings.HasPrefix(v, "http") {
+		return strings.TrimSpace(v)
+	}
+
+	var byline string
+	doc.Find(`[rel="author"], [itemprop="author"], [class], [id]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		n := s.Nodes[0]
+		if n.Data == "meta" || n.Data == "link" || hiddenElement(strings.ToLower(n.Data), n.Attr) {
+			return true
+		}
+		rel, _ := attr(n.Attr, "rel")
+		prop, _ := attr(n.Attr, "itemprop")
+		cls, _ := attr(n.Attr, "class")
+		id, _ := attr(n.Attr, "id")
+		if rel != "author" && prop != "author" && !bylineRe.MatchString(cls+" "+id) {
+			return true
+		}
+		text := strings.Join(strings.Fields(s.Text()), " ")
+		if text != "" && len(text) < 100 {
+			byline = text
+			return false
+		}
+		return true
+	})
+	return byline
+}
@@ -0,0 +1,113 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html><head>
+  <title>How Widgets Are Made | Example News</title>
+  <meta name="author" content="Ada Lovelace">
+</head><body>
+  <header><nav><a href="/">Home</a> <a href="/news">News</a> <a href="/about">About</a></nav></header>
+  <div id="sidebar" class="sidebar"><p>Subscribe to our newsletter, it has news, deals, offers and more every single week.</p></div>
+  <div class="article-body">
+    <h1>How Widgets Are Made</h1>
+    <p>Widgets are made in factories, by people and machines, every day of the week. It is a long process.</p>
+    <p>First, the <strong>raw material</strong> is cut, shaped and polished. See <a href="/process">the process</a> for details.</p>
+    <img src="/img/widget.jpg" alt="A shiny widget">
+    <ul><li>Cut</li><li>Shape</li><li>Polish</li></ul>
+    <pre>make widget --fast</pre>
+    <p hidden>Hidden paragraph, which should never appear in the output at all.</p>
+    <script>track()</script>
+  </div>
+  <div class="comments"><p>Great article, thanks, I learned a lot about widgets and their making.</p></div>
+  <footer><p>Copyright Example News, all rights reserved, since the beginning of time.</p></footer>
+</body></html>`
+
+func TestExtractContent(t *testing.T) {
+	res, err := parser.Parse(strings.NewReader(articlePage), mustURL("https://news.example/2024/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := parser.ExtractContent(res.Doc, mustURL(res.BaseURL))
+
+	if c.Title != "How Widgets Are Made" {
+		t.Errorf("unexpected title %q", c.Title)
+	}
+	if c.Byline != "Ada Lovelace" {
+		t.Errorf("unexpected byline %q", c.Byline)
+	}
+
+	want := strings.Join([]string{
+		"# How Widgets Are Made",
+		"Widgets are made in factories, by people and machines, every day of the week. It is a long process.",
+		"First, the **raw material** is cut, shaped and polished. See [the process](https://news.example/process) for details.",
+		"![A shiny widget](https://news.example/img/widget.jpg)",
+		"- Cut\n- Shape\n- Polish",
+		"```\nmake widget --fast\n```",
+	}, "\n\n")
+	if c.Markdown != want {
+		t.Errorf("unexpected markdown:\n%s\nwant:\n%s", c.Markdown, want)
+	}
+
+	if len(c.Images) != 1 || c.Images[0] != (parser.ContentImage{URL: "https://news.example/img/widget.jpg", Alt: "A shiny widget"}) {
+		t.Errorf("unexpected images %+v", c.Images)
+	}
+}
+
+func TestExtractContent_MarkdownElements(t *testing.T) {
+	page := `<html><head><title>Short</title></head><body><article>
+	  <p>Intro with <em>emphasis</em>, a <code>snippet</code>, an_underscore and a line<br>break, which is long enough.</p>
+	  <blockquote><p>Quoted text, with a comma, and more words here.</p></blockquote>
+	  <ol start="3"><li>Third<ul><li>Nested</li></ul></li><li>Fourth</li></ol>
+	  <table><tr><th>Name</th><th>Size</th></tr><tr><td>A|B</td><td>1</td></tr></table>
+	  <span class="byline">By Grace Hopper</span>
+	</article></body></html>`
+	res, err := parser.Parse(strings.NewReader(page), mustURL("http://example.com/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := parser.ExtractContent(res.Doc, mustURL(res.BaseURL))
+
+	for _, want := range []string{
+		"Intro with _emphasis_, a `snippet`, an\\_underscore and a line  \nbreak, which is long enough.",
+		"> Quoted text, with a comma, and more words here.",
+		"3. Third\n\n   - Nested\n4. Fourth",
+		"| Name | Size |\n| --- | --- |\n| A\\|B | 1 |",
+	} {
+		if !strings.Contains(c.Markdown, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, c.Markdown)
+		}
+	}
+	if c.Title != "Short" || c.Byline != "By Grace Hopper" {
+		t.Errorf("unexpected title %q or byline %q", c.Title, c.Byline)
+	}
+}
+
+func TestExtractContent_EscapesMarkdownSyntax(t *testing.T) {
+	page := `<html><body><article>
+	  <p>Use &lt;div&gt; &amp; friends, which is quite long enough to count.</p>
+	  <p># not a heading<br>- not a list<br>+ nor this<br>&gt; not a quote<br>2024. A year to remember</p>
+	</article></body></html>`
+	res, err := parser.Parse(strings.NewReader(page), mustURL("http://example.com/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := parser.ExtractContent(res.Doc, mustURL(res.BaseURL))
+
+	for _, want := range []string{
+		`Use \<div\> \& friends, which is quite long enough to count.`,
+		"\\# not a heading  \n\\- not a list  \n\\+ nor this  \n\\> not a quote  \n2024\\. A year to remember",
+	} {
+		if !strings.Contains(c.Markdown, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, c.Markdown)
+		}
+	}
+}
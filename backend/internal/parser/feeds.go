@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Feed is a <link rel="alternate"> to an RSS, Atom or JSON feed.
+type Feed struct {
+	// Type is the lower-cased media type.
+	Type  string
+	Title string
+	// Href is the attribute as written; URL is Href resolved against the
+	// document base, or empty when it cannot be resolved.
+	Href string
+	URL  string
+}
+
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// feedRef extracts a feed link from a <link> element.
+func feedRef(attrs []html.Attribute) (Feed, bool) {
+	rel, _ := attr(attrs, "rel")
+	typ, _ := attr(attrs, "type")
+	href, _ := attr(attrs, "href")
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if !hasToken(rel, "alternate") || !feedTypes[typ] || strings.TrimSpace(href) == "" {
+		return Feed{}, false
+	}
+	title, _ := attr(attrs, "title")
+	return Feed{Type: typ, Title: strings.TrimSpace(title), Href: strings.TrimSpace(href)}, true
+}
+
+// manifestRef returns the href of a <link rel="manifest">.
+func manifestRef(attrs []html.Attribute) (string, bool) {
+	rel, _ := attr(attrs, "rel")
+	href, _ := attr(attrs, "href")
+	href = strings.TrimSpace(href)
+	return href, hasToken(rel, "manifest") && href != ""
+}
+
+func resolveFeeds(feeds []Feed, base *url.URL) []Feed {
+	for i := range feeds {
+		feeds[i].URL = resolveHref(base, feeds[i].Href)
+	}
+	return feeds
+}
+
+// resolveHref resolves href against base, or returns "" when it does not
+// give an absolute URL.
+func resolveHref(base *url.URL, href string) string {
+	if href == "" {
+		return ""
+	}
+	u, err := base.Parse(href)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+// collectSiteLinks returns the feed links and the first manifest link.
+func collectSiteLinks(doc *goquery.Document, base *url.URL) ([]Feed, string) {
+	var feeds []Feed
+	var manifest string
+	doc.Find("link").Each(func(_ int, s *goquery.Selection) {
+		attrs := s.Nodes[0].Attr
+		if f, ok := feedRef(attrs); ok {
+			feeds = append(feeds, f)
+		}
+		if m, ok := manifestRef(attrs); ok && manifest == "" {
+			manifest = m
+		}
+	})
+	return resolveFeeds(feeds, base), resolveHref(base, manifest)
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Form describes one <form> element on the page.
+type Form struct {
+	Element string
+	// Method is upper-cased and defaults to GET.
+	Method string
+	// Action is the resolved submission URL. A missing or empty action
+	// submits to the document URL.
+	Action       string
+	Autocomplete string
+	Inputs       []FormInput
+	HasPassword  bool
+	HasCSRFToken bool
+}
+
+// FormInput is a field inside a form.
+type FormInput struct {
+	Tag          string
+	Type         string
+	Name         string
+	Autocomplete string
+}
+
+// csrfNameRe matches names commonly used for anti-CSRF hidden fields.
+var csrfNameRe = regexp.MustCompile(`(?i)(csrf|xsrf|authenticity_token|requestverificationtoken|^_token$|^__token$|anti.?forgery|form_key|nonce)`)
+
+// formInfo accumulates what the form inventory and login detection need to
+// know about one form. It is fed element by element so the tree and
+// streaming parsers share it.
+type formInfo struct {
+	element string
+	labels  []string
+
+	method       string
+	action       string
+	hasAction    bool
+	autocomplete string
+	inputs       []FormInput
+	csrf         bool
+
+	passwords       int
+	currentPassword bool
+	newPassword     bool
+	usernameField   bool
+	strongField     bool
+	weakField       bool
+	submit          bool
+}
+
+func newFormInfo(attrs []html.Attribute) *formInfo {
+	f := &formInfo{element: describeElement("form", attrs)}
+	for _, k := range []string{"action", "id", "class", "name"} {
+		if v, ok := attr(attrs, k); ok {
+			f.labels = append(f.labels, v)
+		}
+	}
+	f.method, _ = attr(attrs, "method")
+	f.action, f.hasAction = attr(attrs, "action")
+	f.autocomplete, _ = attr(attrs, "autocomplete")
+	return f
+}
+
+func (f *formInfo) observe(tag string, attrs []html.Attribute) {
+	switch tag {
+	case "button":
+		f.submit = true
+	case "select", "textarea":
+		f.addInput(tag, "", attrs)
+	case "input":
+		typ, _ := attr(attrs, "type")
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		if typ == "" {
+			typ = "text"
+		}
+		f.addInput(tag, typ, attrs)
+
+		switch typ {
+		case "password":
+			f.passwords++
+		case "submit", "button", "image":
+			f.submit = true
+			if v, ok := attr(attrs, "value"); ok {
+				f.labels = append(f.labels, v)
+			}
+		case "hidden":
+			if name, _ := attr(attrs, "name"); csrfNameRe.MatchString(name) {
+				f.csrf = true
+			}
+		}
+		ac, _ := attr(attrs, "autocomplete")
+		for _, tok := range strings.Fields(strings.ToLower(ac)) {
+			switch tok {
+			case "current-password":
+				f.currentPassword = true
+			case "new-password":
+				f.newPassword = true
+			case "username":
+				f.usernameField = true
+			}
+		}
+		for _, k := range []string{"name", "id", "autocomplete", "placeholder"} {
+			v, ok := attr(attrs, k)
+			if !ok {
+				continue
+			}
+			v = strings.ToLower(v)
+			if containsAny(v, strongAuthHints) {
+				f.strongField = true
+			} else if containsAny(v, weakAuthHints) {
+				f.weakField = true
+			}
+		}
+	}
+}
+
+func (f *formInfo) addInput(tag, typ string, attrs []html.Attribute) {
+	name, _ := attr(attrs, "name")
+	ac, _ := attr(attrs, "autocomplete")
+	f.inputs = append(f.inputs, FormInput{Tag: tag, Type: typ, Name: name, Autocomplete: ac})
+}
+
+// observeButtonText records the label of a <button> inside the form.
+func (f *formInfo) observeButtonText(text string) {
+	f.labels = append(f.labels, text)
+}
+
+// form resolves the collected form against the document base.
+func (f *formInfo) form(base, docURL *url.URL) Form {
+	method := strings.ToUpper(strings.TrimSpace(f.method))
+	if method != "POST" && method != "DIALOG" {
+		method = "GET"
+	}
+	action := docURL.String()
+	if a := strings.TrimSpace(f.action); f.hasAction && a != "" {
+		if u, err := base.Parse(a); err == nil {
+			action = u.String()
+		}
+	}
+	return Form{
+		Element:      f.element,
+		Method:       method,
+		Action:       action,
+		Autocomplete: strings.ToLower(strings.TrimSpace(f.autocomplete)),
+		Inputs:       f.inputs,
+		HasPassword:  f.passwords > 0,
+		HasCSRFToken: f.csrf,
+	}
+}
+
+func collectForms(doc *goquery.Document) []*formInfo {
+	var forms []*formInfo
+	doc.Find("form").Each(func(_ int, f *goquery.Selection) {
+		info := newFormInfo(f.Nodes[0].Attr)
+		f.Find("input, button, select, textarea").Each(func(_ int, el *goquery.Selection) {
+			n := el.Nodes[0]
+			info.observe(n.Data, n.Attr)
+			if n.Data == "button" {
+				info.observeButtonText(el.Text())
+			}
+		})
+		forms = append(forms, info)
+	})
+	return forms
+}
+
+func resolveForms(forms []*formInfo, base, docURL *url.URL) []Form {
+	var out []Form
+	for _, f := range forms {
+		out = append(out, f.form(base, docURL))
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestParse_Forms(t *testing.T) {
+	html := `
+	<html><head><base href="https://test.local/app/"></head>
+	<body>
+	  <form id="login" method="post" action="session" autocomplete="off">
+	    <input type="hidden" name="authenticity_token" value="abc">
+	    <input name="user" autocomplete="username">
+	    <input type="password" name="pass" autocomplete="current-password">
+	    <select name="lang"></select>
+	    <button>Sign in</button>
+	  </form>
+	  <form class="search">
+	    <input type="search" name="q">
+	    <textarea name="notes"></textarea>
+	  </form>
+	</body></html>`
+
+	res, err := parser.Parse(strings.NewReader(html), mustURL("https://test.local/page"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Forms) != 2 {
+		t.Fatalf("expected 2 forms, got %d", len(res.Forms))
+	}
+
+	login := res.Forms[0]
+	if login.Element != "form#login" || login.Method != "POST" || login.Action != "https://test.local/app/session" {
+		t.Errorf("unexpected login form: %+v", login)
+	}
+	if !login.HasPassword || !login.HasCSRFToken || login.Autocomplete != "off" {
+		t.Errorf("expected password, CSRF token and autocomplete=off: %+v", login)
+	}
+	if len(login.Inputs) != 4 {
+		t.Fatalf("expected 4 inputs, got %+v", login.Inputs)
+	}
+	if in := login.Inputs[2]; in.Type != "password" || in.Name != "pass" || in.Autocomplete != "current-password" {
+		t.Errorf("unexpected password input: %+v", in)
+	}
+	if in := login.Inputs[3]; in.Tag != "select" || in.Name != "lang" {
+		t.Errorf("unexpected select input: %+v", in)
+	}
+
+	search := res.Forms[1]
+	if search.Method != "GET" || search.Action != "https://test.local/page" {
+		t.Errorf("expected GET to the document URL, got %s %s", search.Method, search.Action)
+	}
+	if search.HasPassword || search.HasCSRFToken {
+		t.Errorf("unexpected flags on search form: %+v", search)
+	}
+	if len(search.Inputs) != 2 || search.Inputs[0].Type != "search" || search.Inputs[1].Tag != "textarea" {
+		t.Errorf("unexpected search inputs: %+v", search.Inputs)
+	}
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Image is an <img> element, in document order.
+type Image struct {
+	// Src is the src attribute as written. Data URIs are cut after the
+	// comma, e.g. "data:image/png;base64,", and DataBytes holds the length of
+	// the whole attribute.
+	Src       string
+	DataBytes int
+	// URL is Src resolved against the document base, or empty for data URIs
+	// and URLs that cannot be resolved.
+	URL     string
+	Width   string
+	Height  string
+	Loading string
+	Srcset  bool
+	// ModernSource reports whether an enclosing <picture> offers a WebP or
+	// AVIF <source> before the image.
+	ModernSource bool
+}
+
+// imageRef describes an <img> element. inModernPicture reports whether an
+// enclosing <picture> has offered a modern source so far.
+func imageRef(attrs []html.Attribute, inModernPicture bool) Image {
+	get := func(key string) string {
+		v, _ := attr(attrs, key)
+		return strings.TrimSpace(v)
+	}
+	img := Image{
+		Src:          get("src"),
+		Width:        get("width"),
+		Height:       get("height"),
+		Loading:      strings.ToLower(get("loading")),
+		Srcset:       get("srcset") != "",
+		ModernSource: inModernPicture,
+	}
+	if len(img.Src) >= 5 && strings.EqualFold(img.Src[:5], "data:") {
+		img.DataBytes = len(img.Src)
+		if comma := strings.IndexByte(img.Src, ','); comma >= 0 {
+			img.Src = img.Src[:comma+1]
+		}
+	}
+	return img
+}
+
+// modernSource reports whether a <picture> <source> offers WebP or AVIF,
+// by type or by the extension of its candidates.
+func modernSource(attrs []html.Attribute) bool {
+	if typ, _ := attr(attrs, "type"); typ != "" {
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		return typ == "image/webp" || typ == "image/avif"
+	}
+	v, _ := attr(attrs, "srcset")
+	for _, c := range srcsetURLs(v) {
+		u, err := url.Parse(c)
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".webp", ".avif":
+			return true
+		}
+	}
+	return false
+}
+
+func resolveImages(imgs []Image, base *url.URL) []Image {
+	for i := range imgs {
+		if imgs[i].Src == "" || imgs[i].DataBytes > 0 {
+			continue
+		}
+		if u, err := base.Parse(imgs[i].Src); err == nil && u.Host != "" {
+			imgs[i].URL = u.String()
+		}
+	}
+	return imgs
+}
+
+func collectImages(root *html.Node, base *url.URL) []Image {
+	var imgs []Image
+	// modern is nil outside <picture>.
+	var walk func(n *html.Node, modern *bool)
+	walk = func(n *html.Node, modern *bool) {
+		if n.Type == html.ElementNode && n.Namespace == "" {
+			switch strings.ToLower(n.Data) {
+			case "picture":
+				modern = new(bool)
+			case "source":
+				if modern != nil && modernSource(n.Attr) {
+					*modern = true
+				}
+			case "img":
+				imgs = append(imgs, imageRef(n.Attr, modern != nil && *modern))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, modern)
+		}
+	}
+	walk(root, nil)
+	return resolveImages(imgs, base)
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// InlineCode is the markup, besides inline scripts, that a Content Security
+// Policy without 'unsafe-inline' blocks.
+type InlineCode struct {
+	// Handlers are the on* event handler attributes.
+	Handlers []AttrValue
+	// JSURLs are javascript: URLs in href, src, action and formaction.
+	JSURLs []AttrValue
+	// Styles are the style attributes.
+	Styles []AttrValue
+}
+
+// urlAttrs are the attributes that navigate to, or load, a URL.
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true,
+}
+
+// observe records the inline code carried by one element's attributes.
+func (c *InlineCode) observe(tag string, attrs []html.Attribute) {
+	for _, a := range attrs {
+		if a.Namespace != "" {
+			continue
+		}
+		name := strings.ToLower(a.Key)
+		v := AttrValue{Tag: tag, Name: name, Value: a.Val}
+		switch {
+		case len(name) > 2 && strings.HasPrefix(name, "on"):
+			c.Handlers = appendAttr(c.Handlers, v)
+		case urlAttrs[name] && javascriptURL(a.Val):
+			c.JSURLs = appendAttr(c.JSURLs, v)
+		case name == "style" && strings.TrimSpace(a.Val) != "":
+			c.Styles = appendAttr(c.Styles, v)
+		}
+	}
+}
+
+func appendAttr(list []AttrValue, v AttrValue) []AttrValue {
+	if len(list) == maxAttrValues {
+		return list
+	}
+	return append(list, v)
+}
+
+// javascriptURL reports whether v uses the javascript: scheme. Browsers
+// ignore leading spaces and tabs or newlines inside the scheme.
+func javascriptURL(v string) bool {
+	v = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, strings.TrimLeft(v, " \t\n\f\r\x00"))
+	return len(v) >= 11 && strings.EqualFold(v[:11], "javascript:")
+}
+
+func collectInlineCode(root *html.Node) InlineCode {
+	var c InlineCode
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			c.observe(strings.ToLower(n.Data), n.Attr)
+		}
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(root)
+	return c
+}
@@ -0,0 +1,357 @@
+package parser
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Login candidate kinds.
+const (
+	LoginKindLogin         = "login"
+	LoginKindSignup        = "signup"
+	LoginKindPasswordReset = "password_reset"
+	LoginKindLink          = "login_link"
+)
+
+// loginThreshold is the confidence a login or login_link candidate needs
+// before the page is reported as offering a login.
+const loginThreshold = 0.4
+
+// maxLoginCandidates bounds how many candidates are reported.
+const maxLoginCandidates = 5
+
+// LoginCandidate is a form or element that looks like part of an
+// authentication flow.
+type LoginCandidate struct {
+	Kind       string
+	Confidence float64
+	Element    string
+	Reasons    []string
+}
+
+// LoginDetection summarizes the strongest candidate on the page. Present
+// reports whether any login form, SSO button or login link reached the
+// confidence threshold; signup and password reset forms do not count.
+type LoginDetection struct {
+	Present    bool
+	Kind       string
+	Confidence float64
+	Element    string
+	Reasons    []string
+	Candidates []LoginCandidate
+}
+
+var (
+	// Field name hints. Email alone is weak: newsletter forms have one too.
+	strongAuthHints = []string{"login", "signin", "sign-in", "username", "user", "pwd", "password", "passcode"}
+	weakAuthHints   = []string{"email", "e-mail"}
+
+	loginWords  = []string{"log in", "login", "log-in", "sign in", "signin", "sign-in", "log on", "logon"}
+	signupWords = []string{"sign up", "signup", "sign-up", "register", "registration", "create account", "create an account", "join"}
+	resetWords  = []string{"forgot", "reset", "recover", "lost password"}
+
+	loginSegments = map[string]bool{
+		"login": true, "log-in": true, "signin": true, "sign-in": true, "sign_in": true, "logon": true,
+	}
+
+	ssoProviders = []struct{ key, name string }{
+		{"google", "Google"},
+		{"apple", "Apple"},
+		{"facebook", "Facebook"},
+		{"microsoft", "Microsoft"},
+		{"github", "GitHub"},
+		{"twitter", "Twitter"},
+		{"linkedin", "LinkedIn"},
+	}
+	ssoEndpoints = []struct{ host, path, name string }{
+		{"accounts.google.com", "/", "Google"},
+		{"appleid.apple.com", "/auth", "Apple"},
+		{"login.microsoftonline.com", "/", "Microsoft"},
+		{"github.com", "/login/oauth", "GitHub"},
+		{"www.facebook.com", "/dialog/oauth", "Facebook"},
+	}
+	ssoVerbs = []string{"sign in with", "log in with", "login with", "continue with", "sign up with"}
+)
+
+func containsAny(s string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether s contains one of words as a whole word, so
+// that "join" matches "Join now" but not "adjoining".
+func containsWord(s string, words []string) bool {
+	for _, w := range words {
+		for i := 0; ; {
+			j := strings.Index(s[i:], w)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(w)
+			if !wordByteAt(s, start-1) && !wordByteAt(s, end) {
+				return true
+			}
+			i = start + 1
+		}
+	}
+	return false
+}
+
+func wordByteAt(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c >= 0x80
+}
+
+func normalizeText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// candidate scores the form, or returns nil when nothing about it relates to
+// authentication.
+func (f *formInfo) candidate() *LoginCandidate {
+	if f.passwords == 0 && !f.currentPassword && !f.newPassword && !((f.strongField || f.weakField) && f.submit) {
+		return nil
+	}
+
+	var reasons []string
+	score := 0.0
+	add := func(v float64, reason string) {
+		score += v
+		reasons = append(reasons, reason)
+	}
+
+	switch {
+	case f.passwords == 1:
+		add(0.5, "password field")
+	case f.passwords > 1:
+		add(0.4, "multiple password fields")
+	}
+	if f.currentPassword {
+		add(0.3, "autocomplete=current-password")
+	}
+	if f.newPassword {
+		add(0.2, "autocomplete=new-password")
+	}
+	if f.usernameField {
+		add(0.1, "autocomplete=username")
+	}
+	switch {
+	case f.strongField:
+		add(0.2, "credential-like field names")
+	case f.weakField:
+		add(0.1, "email field")
+	}
+	if f.submit {
+		add(0.1, "submit control")
+	}
+
+	labels := normalizeText(strings.Join(f.labels, " "))
+	signup := f.newPassword || f.passwords > 1
+	reset := containsWord(labels, resetWords)
+	signedIn := containsWord(labels, loginWords)
+	if containsWord(labels, signupWords) {
+		signup = true
+		reasons = append(reasons, "labelled as sign-up")
+	}
+	if reset {
+		reasons = append(reasons, "labelled as password reset")
+	}
+	if signedIn {
+		add(0.1, "labelled as sign-in")
+	}
+
+	// A "Forgot password?" link inside a login form does not make it a reset
+	// form: those have no password field, or only new ones.
+	kind := LoginKindLogin
+	switch {
+	case f.currentPassword:
+	case f.passwords == 1 && !f.newPassword && signedIn:
+	case reset && (f.passwords == 0 || f.newPassword):
+		kind = LoginKindPasswordReset
+	case signup:
+		kind = LoginKindSignup
+	}
+	// An email field and a submit button alone is a newsletter or contact
+	// form, not a login.
+	if kind == LoginKindLogin && f.passwords == 0 && !f.currentPassword && !f.strongField && !f.usernameField {
+		return nil
+	}
+
+	return &LoginCandidate{
+		Kind:       kind,
+		Confidence: roundScore(score),
+		Element:    f.element,
+		Reasons:    reasons,
+	}
+}
+
+// ctaCandidate classifies a link, or a button outside any form: SSO buttons
+// count as a login, sign-in links as a login_link. Generic "account" links
+// are deliberately ignored.
+func ctaCandidate(tag string, attrs []html.Attribute, text string) *LoginCandidate {
+	text = normalizeText(text)
+	href, _ := attr(attrs, "href")
+	element := describeElement(tag, attrs)
+
+	if provider := ssoProvider(text, href); provider != "" {
+		return &LoginCandidate{
+			Kind:       LoginKindLogin,
+			Confidence: 0.6,
+			Element:    element,
+			Reasons:    []string{"SSO button (" + provider + ")"},
+		}
+	}
+
+	var reasons []string
+	score := 0.0
+	if text != "" && len(text) <= 40 && containsWord(text, loginWords) && !containsWord(text, signupWords) {
+		score += 0.4
+		reasons = append(reasons, "link text "+quoteShort(text))
+	}
+	if tag == "a" && isLoginPath(href) {
+		score += 0.1
+		reasons = append(reasons, "href points at a sign-in page")
+	}
+	if score < 0.4 {
+		return nil
+	}
+	return &LoginCandidate{
+		Kind:       LoginKindLink,
+		Confidence: roundScore(score),
+		Element:    element,
+		Reasons:    reasons,
+	}
+}
+
+func ssoProvider(text, href string) string {
+	for _, verb := range ssoVerbs {
+		i := strings.Index(text, verb)
+		if i < 0 {
+			continue
+		}
+		rest := text[i+len(verb):]
+		for _, p := range ssoProviders {
+			if strings.Contains(rest, p.key) {
+				return p.name
+			}
+		}
+	}
+	if u, err := url.Parse(strings.TrimSpace(href)); err == nil {
+		host := strings.ToLower(u.Host)
+		for _, e := range ssoEndpoints {
+			if host == e.host && strings.HasPrefix(u.Path, e.path) {
+				return e.name
+			}
+		}
+	}
+	return ""
+}
+
+func isLoginPath(href string) bool {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return false
+	}
+	for _, seg := range strings.Split(strings.ToLower(u.Path), "/") {
+		if loginSegments[seg] {
+			return true
+		}
+	}
+	return false
+}
+
+// rankLoginCandidates orders candidates by confidence, keeping document
+// order for ties, and summarizes them.
+func rankLoginCandidates(forms, ctas []LoginCandidate) LoginDetection {
+	all := append(forms, ctas...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Confidence > all[j].Confidence
+	})
+
+	var det LoginDetection
+	for _, c := range all {
+		if (c.Kind == LoginKindLogin || c.Kind == LoginKindLink) && c.Confidence >= loginThreshold {
+			det.Present = true
+			break
+		}
+	}
+	if len(all) > maxLoginCandidates {
+		all = all[:maxLoginCandidates]
+	}
+	if len(all) > 0 {
+		best := all[0]
+		det.Kind = best.Kind
+		det.Confidence = best.Confidence
+		det.Element = best.Element
+		det.Reasons = best.Reasons
+		det.Candidates = all
+	}
+	return det
+}
+
+func collectLogin(doc *goquery.Document, forms []*formInfo) LoginDetection {
+	var formCands, ctas []LoginCandidate
+	for _, f := range forms {
+		if c := f.candidate(); c != nil {
+			formCands = append(formCands, *c)
+		}
+	}
+	doc.Find("a, button").Each(func(_ int, s *goquery.Selection) {
+		n := s.Nodes[0]
+		if n.Data == "button" && s.Closest("form").Length() > 0 {
+			return
+		}
+		if c := ctaCandidate(n.Data, n.Attr, s.Text()); c != nil {
+			ctas = append(ctas, *c)
+		}
+	})
+	return rankLoginCandidates(formCands, ctas)
+}
+
+// describeElement renders a short, selector-like description of an element,
+// e.g. form#login or a.nav-link[href="/signin"].
+func describeElement(tag string, attrs []html.Attribute) string {
+	var b strings.Builder
+	b.WriteString(tag)
+	if id, _ := attr(attrs, "id"); strings.TrimSpace(id) != "" {
+		b.WriteString("#" + strings.TrimSpace(id))
+		return b.String()
+	}
+	cls, _ := attr(attrs, "class")
+	for i, c := range strings.Fields(cls) {
+		if i == 2 {
+			break
+		}
+		b.WriteString("." + c)
+	}
+	for _, k := range []string{"action", "href", "name"} {
+		if v, ok := attr(attrs, k); ok && v != "" {
+			if r := []rune(v); len(r) > 80 {
+				v = string(r[:80]) + "…"
+			}
+			b.WriteString("[" + k + "=" + quoteShort(v) + "]")
+			break
+		}
+	}
+	return b.String()
+}
+
+func quoteShort(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(math.Min(v, 1)*100) / 100
+}
@@ -0,0 +1,154 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestParse_LoginDetection(t *testing.T) {
+	cases := []struct {
+		name        string
+		html        string
+		present     bool
+		kind        string
+		element     string
+		minConf     float64
+		wantReasons []string
+	}{
+		{
+			name: "login form",
+			html: `<form id="signin" action="/session">
+				<input name="username" autocomplete="username">
+				<input type="password" autocomplete="current-password">
+				<button>Log in</button>
+			</form>`,
+			present:     true,
+			kind:        parser.LoginKindLogin,
+			element:     "form#signin",
+			minConf:     0.9,
+			wantReasons: []string{"password field", "autocomplete=current-password"},
+		},
+		{
+			name: "signup form",
+			html: `<form action="/register">
+				<input type="email" name="email">
+				<input type="password" name="password" autocomplete="new-password">
+				<input type="password" name="confirm" autocomplete="new-password">
+				<button>Create account</button>
+			</form>`,
+			present:     false,
+			kind:        parser.LoginKindSignup,
+			element:     `form[action="/register"]`,
+			wantReasons: []string{"multiple password fields", "labelled as sign-up"},
+		},
+		{
+			name: "password reset form",
+			html: `<form class="forgot-password" action="/password/reset">
+				<input type="email" name="email" placeholder="Email">
+				<button>Send reset link</button>
+			</form>`,
+			present:     false,
+			kind:        parser.LoginKindPasswordReset,
+			element:     `form.forgot-password[action="/password/reset"]`,
+			wantReasons: []string{"labelled as password reset"},
+		},
+		{
+			name:        "login link",
+			html:        `<nav><a class="nav-link" href="/signin">Sign in</a></nav>`,
+			present:     true,
+			kind:        parser.LoginKindLink,
+			element:     `a.nav-link[href="/signin"]`,
+			minConf:     0.5,
+			wantReasons: []string{`link text "sign in"`, "href points at a sign-in page"},
+		},
+		{
+			name:        "sso button",
+			html:        `<button class="google">Continue with Google</button>`,
+			present:     true,
+			kind:        parser.LoginKindLogin,
+			element:     "button.google",
+			wantReasons: []string{"SSO button (Google)"},
+		},
+		{
+			name: "account link and newsletter are not logins",
+			html: `<a href="/account">My Account</a>
+				<form action="/subscribe"><input type="email" name="email"><button>Subscribe</button></form>`,
+			present: false,
+		},
+		{
+			name: "forgot password button in a login form",
+			html: `<form class="auth" action="/session">
+				<input name="email" type="email">
+				<input type="password" name="pass">
+				<button>Continue</button>
+				<button type="button">Forgot password?</button>
+			</form>`,
+			present: true,
+			kind:    parser.LoginKindLogin,
+			element: `form.auth[action="/session"]`,
+		},
+		{
+			name: "join is matched as a word",
+			html: `<form action="/joint-accounts/session">
+				<input name="username">
+				<input type="password" name="pass">
+				<button>Continue</button>
+			</form>`,
+			present: true,
+			kind:    parser.LoginKindLogin,
+			element: `form[action="/joint-accounts/session"]`,
+		},
+		{
+			name: "long action is cut on a character boundary",
+			html: `<form action="/` + strings.Repeat("é", 100) + `">
+				<input name="username">
+				<input type="password" name="pass">
+				<button>Continue</button>
+			</form>`,
+			present: true,
+			kind:    parser.LoginKindLogin,
+			element: `form[action="/` + strings.Repeat("é", 79) + `…"]`,
+		},
+		{
+			name:    "nothing",
+			html:    `<p>Hello</p><a href="/about">About</a>`,
+			present: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parser.Parse(strings.NewReader("<html><body>"+tc.html+"</body></html>"), mustURL("http://test.local/"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := res.Login
+
+			if got.Present != tc.present || res.LoginFormPresent != tc.present {
+				t.Errorf("expected present=%v, got %v (%+v)", tc.present, got.Present, got)
+			}
+			if got.Kind != tc.kind {
+				t.Errorf("expected kind %q, got %q", tc.kind, got.Kind)
+			}
+			if got.Element != tc.element {
+				t.Errorf("expected element %q, got %q", tc.element, got.Element)
+			}
+			if got.Confidence < tc.minConf {
+				t.Errorf("expected confidence >= %.2f, got %.2f", tc.minConf, got.Confidence)
+			}
+			for _, want := range tc.wantReasons {
+				found := false
+				for _, r := range got.Reasons {
+					if r == want {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected reason %q in %v", want, got.Reasons)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package parser
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags start a new Markdown block. Elements not listed here are
+// rendered inline.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"dd": true, "details": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "ul": true,
+}
+
+// markdownEscaper escapes text that CommonMark would otherwise read as
+// emphasis, code, links, raw HTML or entities. Block markers at the start of
+// a line are escaped separately, by paragraph.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "&", `\&`,
+)
+
+// markdownWriter renders HTML nodes as CommonMark and collects the images
+// it encounters.
+type markdownWriter struct {
+	base   *url.URL
+	images []ContentImage
+	seen   map[string]bool
+}
+
+func newMarkdownWriter(base *url.URL) *markdownWriter {
+	return &markdownWriter{base: base, seen: map[string]bool{}}
+}
+
+// block renders n as one or more Markdown blocks separated by blank lines.
+func (w *markdownWriter) block(n *html.Node) string {
+	if n.Type != html.ElementNode {
+		return paragraph(w.inline(n))
+	}
+	tag := strings.ToLower(n.Data)
+	if hiddenElement(tag, n.Attr) {
+		return ""
+	}
+
+	switch tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := paragraph(w.inlineChildren(n))
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", int(tag[1]-'0')) + " " + strings.ReplaceAll(text, "  \n", " ")
+	case "p", "dt", "dd", "summary", "figcaption":
+		return paragraph(w.inlineChildren(n))
+	case "hr":
+		return "---"
+	case "pre":
+		code := strings.Trim(textContent(n), "\n")
+		if code == "" {
+			return ""
+		}
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fence + "\n" + code + "\n" + fence
+	case "blockquote":
+		inner := w.blocks(n)
+		if inner == "" {
+			return ""
+		}
+		return prefixLines(inner, "> ", "> ")
+	case "ul", "ol":
+		return w.list(n, tag == "ol")
+	case "table":
+		return w.table(n)
+	default:
+		if !blockTags[tag] {
+			return paragraph(w.inline(n))
+		}
+		return w.blocks(n)
+	}
+}
+
+// blocks renders the children of n, gathering runs of inline content into
+// paragraphs.
+func (w *markdownWriter) blocks(n *html.Node) string {
+	var out []string
+	var inline strings.Builder
+	flush := func() {
+		if p := paragraph(inline.String()); p != "" {
+			out = append(out, p)
+		}
+		inline.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockTags[strings.ToLower(c.Data)] {
+			flush()
+			if b := w.block(c); b != "" {
+				out = append(out, b)
+			}
+			continue
+		}
+		inline.WriteString(w.inline(c))
+	}
+	flush()
+	return strings.Join(out, "\n\n")
+}
+
+func (w *markdownWriter) list(n *html.Node, ordered bool) string {
+	var items []string
+	i := 0
+	if v, ok := attr(n.Attr, "start"); ordered && ok {
+		if s, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			i = s - 1
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || !strings.EqualFold(c.Data, "li") || hiddenElement("li", c.Attr) {
+			continue
+		}
+		i++
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(i) + ". "
+		}
+		body := w.blocks(c)
+		if body == "" {
+			continue
+		}
+		items = append(items, prefixLines(body, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (w *markdownWriter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.ElementNode && strings.EqualFold(c.Data, "tr") {
+			var cells []string
+			for td := c.FirstChild; td != nil; td = td.NextSibling {
+				if td.Type == html.ElementNode && (td.Data == "td" || td.Data == "th") {
+					cell := strings.ReplaceAll(paragraph(w.inlineChildren(td)), "  \n", " ")
+					cells = append(cells, strings.ReplaceAll(cell, "|", `\|`))
+				}
+			}
+			rows = append(rows, cells)
+			return
+		}
+		if c.Type == html.ElementNode && strings.EqualFold(c.Data, "table") && c != n {
+			return
+		}
+		for cc := c.FirstChild; cc != nil; cc = cc.NextSibling {
+			walk(cc)
+		}
+	}
+	walk(n)
+
+	cols := 0
+	for _, r := range rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return ""
+	}
+	var lines []string
+	for i, r := range rows {
+		for len(r) < cols {
+			r = append(r, "")
+		}
+		lines = append(lines, "| "+strings.Join(r, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (w *markdownWriter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(w.inline(c))
+	}
+	return b.String()
+}
+
+// inline renders n as inline Markdown. Whitespace is collapsed later, by
+// paragraph.
+func (w *markdownWriter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return markdownEscaper.Replace(n.Data)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	tag := strings.ToLower(n.Data)
+	if hiddenElement(tag, n.Attr) {
+		return ""
+	}
+	switch tag {
+	case "br":
+		return "\n"
+	case "strong", "b":
+		return wrapInline(w.inlineChildren(n), "**")
+	case "em", "i":
+		return wrapInline(w.inlineChildren(n), "_")
+	case "code", "kbd", "samp":
+		code := strings.Join(strings.Fields(textContent(n)), " ")
+		if code == "" {
+			return ""
+		}
+		if strings.Contains(code, "`") {
+			return "`` " + code + " ``"
+		}
+		return "`" + code + "`"
+	case "a":
+		text := w.inlineChildren(n)
+		href, _ := attr(n.Attr, "href")
+		u, ok := w.resolve(href, "http", "https", "mailto")
+		if !ok || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "[" + strings.TrimSpace(text) + "](" + u + ")"
+	case "img":
+		return w.image(n)
+	default:
+		if blockTags[tag] {
+			// A block inside inline content, e.g. <a><div>…</div></a>.
+			return " " + w.inlineChildren(n) + " "
+		}
+		return w.inlineChildren(n)
+	}
+}
+
+func (w *markdownWriter) image(n *html.Node) string {
+	src, _ := attr(n.Attr, "src")
+	if v, ok := attr(n.Attr, "data-src"); ok && (strings.TrimSpace(src) == "" || strings.HasPrefix(src, "data:")) {
+		src = v
+	}
+	u, ok := w.resolve(src, "http", "https")
+	if !ok {
+		return ""
+	}
+	alt, _ := attr(n.Attr, "alt")
+	alt = strings.Join(strings.Fields(alt), " ")
+	if !w.seen[u] {
+		w.seen[u] = true
+		w.images = append(w.images, ContentImage{URL: u, Alt: alt})
+	}
+	return "![" + markdownEscaper.Replace(alt) + "](" + u + ")"
+}
+
+func (w *markdownWriter) resolve(raw string, schemes ...string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	u, err := w.base.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(u.String()), true
+		}
+	}
+	return "", false
+}
+
+// paragraph collapses whitespace in inline Markdown. Line breaks from <br>
+// become hard breaks.
+func paragraph(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, escapeBlockStart(line))
+		}
+	}
+	return strings.Join(lines, "  \n")
+}
+
+// escapeBlockStart escapes a heading, list or thematic break marker at the
+// start of a line of text, which would otherwise begin a new block. A
+// quote marker is already escaped by markdownEscaper.
+func escapeBlockStart(line string) string {
+	switch line[0] {
+	case '#', '-', '+':
+		return `\` + line
+	}
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	if digits > 0 && digits < len(line) && (line[digits] == '.' || line[digits] == ')') {
+		return line[:digits] + `\` + line[digits:]
+	}
+	return line
+}
+
+// wrapInline emphasises s, keeping surrounding whitespace outside the
+// markers so the result stays valid CommonMark.
+func wrapInline(s, marker string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if l == "" {
+			lines[i] = strings.TrimRight(p, " ")
+		} else {
+			lines[i] = p + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textContent returns all text below n, as written.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
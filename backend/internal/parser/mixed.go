@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// MixedContent is a plain http:// reference found on a page. Active mixed
+// content (scripts, stylesheets, frames, plugins, fonts) is blocked by
+// browsers on https pages; passive content (images, media, form targets) is
+// loaded or submitted with a warning.
+type MixedContent struct {
+	URL    string
+	Kind   string
+	Tag    string
+	Path   string
+	Active bool
+}
+
+// KindForm marks a form action in MixedContent.
+const KindForm = "form"
+
+var fontExts = map[string]bool{".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true}
+
+// FindMixedContent lists every http:// subresource and form action in doc,
+// resolved against base. Callers decide whether the page itself is https.
+func FindMixedContent(doc *goquery.Document, base *url.URL) []MixedContent {
+	var out []MixedContent
+	add := func(n *html.Node, kind, raw string) {
+		u, err := base.Parse(strings.TrimSpace(raw))
+		if err != nil || !strings.EqualFold(u.Scheme, "http") || u.Host == "" {
+			return
+		}
+		out = append(out, MixedContent{
+			URL:    u.String(),
+			Kind:   kind,
+			Tag:    n.Data,
+			Path:   ElementPath(n),
+			Active: isActiveMixed(kind, u),
+		})
+	}
+
+	var walk func(n *html.Node, inMedia bool)
+	walk = func(n *html.Node, inMedia bool) {
+		if n.Type == html.ElementNode {
+			tag := strings.ToLower(n.Data)
+			for _, r := range elementResources(tag, n.Attr, inMedia) {
+				add(n, r.kind, r.raw)
+			}
+			switch tag {
+			case "style":
+				for _, r := range cssRefs(nodeText(n)) {
+					add(n, r.kind, r.raw)
+				}
+			case "form":
+				if a, ok := attr(n.Attr, "action"); ok && strings.TrimSpace(a) != "" {
+					add(n, KindForm, a)
+				}
+			case "video", "audio":
+				inMedia = true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inMedia)
+		}
+	}
+	for _, n := range doc.Nodes {
+		walk(n, false)
+	}
+	return out
+}
+
+// MixedContentFromResources lists the http:// subresources and form actions
+// of a page parsed without a tree, as the streaming parser does. Repeated
+// references appear once and Path is only the tag name.
+func MixedContentFromResources(resources []Resource, forms []Form) []MixedContent {
+	var out []MixedContent
+	for _, r := range resources {
+		u, err := url.Parse(r.URL)
+		if err != nil || !strings.EqualFold(u.Scheme, "http") {
+			continue
+		}
+		out = append(out, MixedContent{URL: r.URL, Kind: r.Kind, Tag: r.Tag, Path: r.Tag, Active: isActiveMixed(r.Kind, u)})
+	}
+	for _, f := range forms {
+		u, err := url.Parse(f.Action)
+		if err != nil || !strings.EqualFold(u.Scheme, "http") || u.Host == "" {
+			continue
+		}
+		out = append(out, MixedContent{URL: f.Action, Kind: KindForm, Tag: "form", Path: f.Element})
+	}
+	return out
+}
+
+func isActiveMixed(kind string, u *url.URL) bool {
+	switch kind {
+	case KindImage, KindMedia, KindIcon, KindForm:
+		return false
+	case KindCSS:
+		// url() in CSS is usually an image; fonts are blockable.
+		return fontExts[strings.ToLower(path.Ext(u.Path))]
+	default:
+		return true
+	}
+}
+
+// ElementPath renders the ancestry of n as a CSS-like path, e.g.
+// html > body > div#main > img.hero.
+func ElementPath(n *html.Node) string {
+	var parts []string
+	for c := n; c != nil; c = c.Parent {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		part := c.Data
+		if id, _ := attr(c.Attr, "id"); strings.TrimSpace(id) != "" {
+			part += "#" + strings.TrimSpace(id)
+		} else if cls, _ := attr(c.Attr, "class"); len(strings.Fields(cls)) > 0 {
+			part += "." + strings.Fields(cls)[0]
+		}
+		parts = append(parts, part)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
@@ -0,0 +1,56 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+func TestFindMixedContent(t *testing.T) {
+	html := `
+	<html><head>
+	  <script src="http://cdn.example.com/app.js"></script>
+	  <link rel="stylesheet" href="https://cdn.example.com/ok.css">
+	  <style>@font-face { src: url(http://fonts.example.com/f.woff2) } .x { background: url(http://img.example.com/bg.png) }</style>
+	</head><body>
+	  <div id="hero"><img class="banner big" src="http://img.example.com/banner.jpg"></div>
+	  <iframe src="http://player.example.com/embed"></iframe>
+	  <video><source src="http://media.example.com/clip.mp4"></video>
+	  <form action="http://test.local/subscribe"></form>
+	  <a href="http://example.com/">links are not subresources</a>
+	</body></html>`
+
+	res, err := parser.Parse(strings.NewReader(html), mustURL("https://test.local/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items := parser.FindMixedContent(res.Doc, mustURL(res.BaseURL))
+
+	type key struct {
+		kind   string
+		active bool
+	}
+	got := map[key]string{}
+	for _, m := range items {
+		got[key{m.Kind, m.Active}] = m.Path
+	}
+	want := map[key]string{
+		{parser.KindScript, true}: "html > head > script",
+		{parser.KindCSS, true}:    "html > head > style",
+		{parser.KindCSS, false}:   "html > head > style",
+		{parser.KindImage, false}: "html > body > div#hero > img.banner",
+		{parser.KindIframe, true}: "html > body > iframe",
+		{parser.KindMedia, false}: "html > body > video > source",
+		{parser.KindForm, false}:  "html > body > form",
+	}
+	for k, path := range want {
+		if got[k] != path {
+			t.Errorf("expected %+v at %q, got %q", k, path, got[k])
+		}
+	}
+	if len(items) != len(want) {
+		t.Errorf("expected %d mixed content items, got %d: %+v", len(want), len(items), items)
+	}
+}
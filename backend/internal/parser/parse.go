@@ -12,39 +12,70 @@ import (
 )
 
 type Parsed struct {
-	HTMLVersion      string
-	Title            string
-	Headings         map[string]int
-	Links            []string
+	HTMLVersion string
+	BaseURL     string
+	Title       string
+	Lang        string
+	Headings    map[string]int
+	Links       []string
+	Resources   []Resource
+	Images      []Image
+	Forms       []Form
+	Alternates  []Alternate
+	// Doctype is the document type declaration, or nil when there is none.
+	Doctype *Doctype
+	// Refresh is the meta refresh the page declares, if any.
+	Refresh *Refresh
+	// RenderBlocking lists the synchronous scripts in <head> and the
+	// stylesheets without a restricting media query.
+	RenderBlocking []Resource
+	// Attributes lists the text-bearing attribute values in document
+	// order.
+	Attributes []AttrValue
+	// InlineCode lists event handler attributes, javascript: URLs and
+	// style attributes.
+	InlineCode InlineCode
+	// Feeds are the RSS, Atom and JSON feeds the page links to.
+	Feeds []Feed
+	// Manifest is the resolved <link rel="manifest"> URL, if any.
+	Manifest string
+	// AMP reports an <html amp> or <html ⚡> document.
+	AMP       bool
+	Relations Relations
+	// Text is the visible text of the document, capped at 1 MiB. Whitespace
+	// is collapsed and each block of text is on its own line.
+	Text             string
+	InlineScripts    []InlineScript
 	LoginFormPresent bool
+	Login            LoginDetection
+
+	// Doc is the parsed document, for callers that need more than the
+	// summary above. It is nil when the document was parsed by ParseStream.
+	Doc *goquery.Document
 }
 
-func Parse(r io.Reader, base *url.URL) (*Parsed, error) {
+// Parse parses the document fetched from docURL. Relative URLs are resolved
+// against the document base URL, which honours <base href>.
+func Parse(r io.Reader, docURL *url.URL) (*Parsed, error) {
 	root, err := html.Parse(r)
 	if err != nil {
-		slog.Error("failed to parse HTML", "base_url", base.String(), "err", err)
+		slog.Error("failed to parse HTML", "base_url", docURL.String(), "err", err)
 		return nil, err
 	}
 	doc := goquery.NewDocumentFromNode(root)
+	base := documentBase(root, docURL)
 
-	ver := detectDoctype(root)
+	dt := findDoctype(root)
 	title := strings.TrimSpace(doc.Find("title").First().Text())
 
-	h := map[string]int{}
+	h := headingCounts()
 	doc.Find("h1,h2,h3,h4,h5,h6").Each(func(_ int, s *goquery.Selection) {
 		if n := s.Nodes; len(n) > 0 {
-			tag := strings.ToLower(n[0].Data)
-			if tag == "h1" || tag == "h2" || tag == "h3" || tag == "h4" || tag == "h5" || tag == "h6" {
+			if tag := strings.ToLower(n[0].Data); isHeading(tag) {
 				h[tag]++
 			}
 		}
 	})
-	for i := 1; i <= 6; i++ {
-		key := "h" + strconv.Itoa(i)
-		if _, ok := h[key]; !ok {
-			h[key] = 0
-		}
-	}
 
 	// Links
 	var links []string
@@ -53,177 +84,197 @@ func Parse(r io.Reader, base *url.URL) (*Parsed, error) {
 		if !ok || href == "" {
 			return
 		}
-		u, err := base.Parse(href)
-		if err == nil && u.Scheme != "" && u.Host != "" {
-			links = append(links, u.String())
+		if u, ok := resolveLink(base, href); ok {
+			links = append(links, u)
 		}
 	})
 
+	resources := collectResources(root, base, docURL)
+
+	forms := collectForms(doc)
+	feeds, manifest := collectSiteLinks(doc, base)
+
 	// Login detection
-	login := hasObviousLoginForm(doc) || hasSimpleAuthCTA(doc)
+	login := collectLogin(doc, forms)
 
 	parsed := &Parsed{
-		HTMLVersion:      ver,
+		HTMLVersion:      dt.version(),
+		BaseURL:          base.String(),
 		Title:            title,
+		Lang:             documentLang(doc),
 		Headings:         h,
 		Links:            links,
-		LoginFormPresent: login,
+		Resources:        resources,
+		Images:           collectImages(root, base),
+		RenderBlocking:   collectRenderBlocking(root, base, docURL),
+		Forms:            resolveForms(forms, base, docURL),
+		Alternates:       collectAlternates(doc, base),
+		Doctype:          dt,
+		Refresh:          collectRefresh(doc, base),
+		Attributes:       collectAttrValues(root),
+		InlineCode:       collectInlineCode(root),
+		Feeds:            feeds,
+		Manifest:         manifest,
+		AMP:              documentAMP(doc),
+		Relations:        collectRelations(doc, base),
+		Text:             visibleText(root),
+		InlineScripts:    collectInlineScripts(doc),
+		LoginFormPresent: login.Present,
+		Login:            login,
+		Doc:              doc,
 	}
 
 	slog.Debug("parsed HTML successfully",
 		"base_url", base.String(),
+		"document_url", docURL.String(),
 		"title", parsed.Title,
 		"headings_total", len(parsed.Headings),
 		"links_total", len(parsed.Links),
+		"resources_total", len(parsed.Resources),
+		"forms_total", len(parsed.Forms),
 		"login_form_present", parsed.LoginFormPresent,
 	)
 
 	return parsed, nil
 }
 
-func detectDoctype(n *html.Node) string {
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if c.Type != html.DoctypeNode {
-			continue
-		}
-
-		name := strings.ToLower(strings.TrimSpace(c.Data))
-		if name != "html" {
-			if name == "" {
-				return "unknown"
-			}
-			return strings.ToUpper(name)
-		}
+// headingCounts returns a heading histogram with every level present.
+func headingCounts() map[string]int {
+	h := make(map[string]int, 6)
+	for i := 1; i <= 6; i++ {
+		h["h"+strconv.Itoa(i)] = 0
+	}
+	return h
+}
 
-		var publicID, systemID string
-		for _, a := range c.Attr {
-			switch strings.ToLower(a.Key) {
-			case "public":
-				publicID = strings.ToLower(strings.TrimSpace(a.Val))
-			case "system":
-				systemID = strings.ToLower(strings.TrimSpace(a.Val))
-			}
-		}
+func isHeading(tag string) bool {
+	return len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6'
+}
 
-		if publicID == "" && systemID == "" {
-			return "HTML5"
-		}
-		if systemID == "about:legacy-compat" && publicID == "" {
-			return "HTML5 (legacy-compat)"
-		}
+// resolveLink resolves an anchor href against the document base and reports
+// whether it is an absolute link worth counting.
+func resolveLink(base *url.URL, href string) (string, bool) {
+	u, err := base.Parse(href)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", false
+	}
+	return u.String(), true
+}
 
-		if strings.Contains(publicID, "xhtml 1.1") {
-			return "XHTML 1.1"
-		}
+// Doctype is a document type declaration with its public and system
+// identifiers, as written.
+type Doctype struct {
+	Name     string
+	PublicID string
+	SystemID string
+}
 
-		if strings.Contains(publicID, "xhtml 1.0") {
-			switch {
-			case strings.Contains(publicID, "strict"):
-				return "XHTML 1.0 Strict"
-			case strings.Contains(publicID, "transitional"):
-				return "XHTML 1.0 Transitional"
-			case strings.Contains(publicID, "frameset"):
-				return "XHTML 1.0 Frameset"
-			default:
-				return "XHTML 1.0"
-			}
+// newDoctype builds a Doctype from a doctype name and the identifiers the
+// tree builder records as attributes.
+func newDoctype(name string, ids []html.Attribute) *Doctype {
+	d := &Doctype{Name: name}
+	for _, a := range ids {
+		switch strings.ToLower(a.Key) {
+		case "public":
+			d.PublicID = a.Val
+		case "system":
+			d.SystemID = a.Val
 		}
+	}
+	return d
+}
 
-		if strings.Contains(publicID, "html 4.01") {
-			switch {
-			case strings.Contains(publicID, "strict"):
-				return "HTML 4.01 Strict"
-			case strings.Contains(publicID, "transitional"):
-				return "HTML 4.01 Transitional"
-			case strings.Contains(publicID, "frameset"):
-				return "HTML 4.01 Frameset"
-			default:
-				return "HTML 4.01"
-			}
-		}
+// version returns the human readable HTML version of d; see doctypeVersion.
+func (d *Doctype) version() string {
+	if d == nil {
+		return "unknown"
+	}
+	var ids []html.Attribute
+	if d.PublicID != "" {
+		ids = append(ids, html.Attribute{Key: "public", Val: d.PublicID})
+	}
+	if d.SystemID != "" {
+		ids = append(ids, html.Attribute{Key: "system", Val: d.SystemID})
+	}
+	return doctypeVersion(d.Name, ids)
+}
 
-		if strings.Contains(publicID, "html 4.0") {
-			return "HTML 4.0"
-		}
-		if strings.Contains(publicID, "html 3.2") {
-			return "HTML 3.2"
-		}
-		if strings.Contains(publicID, "html 2.0") {
-			return "HTML 2.0"
+func findDoctype(n *html.Node) *Doctype {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.DoctypeNode {
+			return newDoctype(c.Data, c.Attr)
 		}
-
-		return "HTML (doctype with identifiers)"
 	}
-	return "unknown"
+	return nil
 }
 
-
-func hasObviousLoginForm(doc *goquery.Document) bool {
-	// Common substrings hinting at auth fields
-	hints := []string{
-		"login", "signin", "sign-in",
-		"username", "user", "email", "e-mail",
-		"pwd", "password", "passcode",
+// doctypeVersion maps a doctype name and its public/system identifiers to a
+// human readable HTML version.
+func doctypeVersion(name string, ids []html.Attribute) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "html" {
+		if name == "" {
+			return "unknown"
+		}
+		return strings.ToUpper(name)
 	}
-	hasHint := func(v string) bool {
-		v = strings.ToLower(v)
-		for _, h := range hints {
-			if strings.Contains(v, h) {
-				return true
-			}
+
+	var publicID, systemID string
+	for _, a := range ids {
+		switch strings.ToLower(a.Key) {
+		case "public":
+			publicID = strings.ToLower(strings.TrimSpace(a.Val))
+		case "system":
+			systemID = strings.ToLower(strings.TrimSpace(a.Val))
 		}
-		return false
 	}
 
-	found := false
-	doc.Find("form").EachWithBreak(func(_ int, f *goquery.Selection) bool {
-		inputs := f.Find("input")
+	if publicID == "" && systemID == "" {
+		return "HTML5"
+	}
+	if systemID == "about:legacy-compat" && publicID == "" {
+		return "HTML5 (legacy-compat)"
+	}
 
-		if inputs.Filter(`[type="password"]`).Length() > 0 {
-			found = true
-			return false
-		}
+	if strings.Contains(publicID, "xhtml 1.1") {
+		return "XHTML 1.1"
+	}
 
-		authField := false
-		inputs.EachWithBreak(func(_ int, inp *goquery.Selection) bool {
-			for _, a := range []string{"name", "id", "autocomplete", "placeholder"} {
-				if v, ok := inp.Attr(a); ok && hasHint(v) {
-					authField = true
-					return false
-				}
-			}
-			return true
-		})
-		if !authField {
-			return true
+	if strings.Contains(publicID, "xhtml 1.0") {
+		switch {
+		case strings.Contains(publicID, "strict"):
+			return "XHTML 1.0 Strict"
+		case strings.Contains(publicID, "transitional"):
+			return "XHTML 1.0 Transitional"
+		case strings.Contains(publicID, "frameset"):
+			return "XHTML 1.0 Frameset"
+		default:
+			return "XHTML 1.0"
 		}
+	}
 
-		if f.Find(`button, input[type="submit"], input[type="button"]`).Length() > 0 {
-			found = true
-			return false
+	if strings.Contains(publicID, "html 4.01") {
+		switch {
+		case strings.Contains(publicID, "strict"):
+			return "HTML 4.01 Strict"
+		case strings.Contains(publicID, "transitional"):
+			return "HTML 4.01 Transitional"
+		case strings.Contains(publicID, "frameset"):
+			return "HTML 4.01 Frameset"
+		default:
+			return "HTML 4.01"
 		}
+	}
 
-		return true
-	})
-	return found
-}
-
-func hasSimpleAuthCTA(doc *goquery.Document) bool {
-	match := func(s string) bool {
-		s = strings.ToLower(strings.TrimSpace(strings.Join(strings.Fields(s), " ")))
-		return strings.Contains(s, "log in") ||
-			strings.Contains(s, "signin") ||
-			strings.Contains(s, "sign in") ||
-			strings.Contains(s, "account")
+	if strings.Contains(publicID, "html 4.0") {
+		return "HTML 4.0"
+	}
+	if strings.Contains(publicID, "html 3.2") {
+		return "HTML 3.2"
+	}
+	if strings.Contains(publicID, "html 2.0") {
+		return "HTML 2.0"
 	}
 
-	found := false
-	doc.Find("button, a").EachWithBreak(func(_ int, s *goquery.Selection) bool {
-		if match(s.Text()) {
-			found = true
-			return false
-		}
-		return true
-	})
-	return found
+	return "HTML (doctype with identifiers)"
 }
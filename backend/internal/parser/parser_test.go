@@ -3,6 +3,7 @@ package parser_test
 import (
 	"io"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -85,3 +86,301 @@ func TestParse_NoTitle(t *testing.T) {
 		t.Errorf("expected empty title, got %q", res.Title)
 	}
 }
+
+func TestParse_Resources(t *testing.T) {
+	html := `
+	<!DOCTYPE html>
+	<html>
+	  <head>
+	    <link rel="stylesheet" href="/main.css">
+	    <link rel="preload" href="/font.woff2" as="font">
+	    <link rel="icon" href="/favicon.ico">
+	    <link rel="canonical" href="/page">
+	    <script src="https://cdn.example.com/lib.js"></script>
+	    <style>body { background: url('/bg.png'); }</style>
+	  </head>
+	  <body>
+	    <img src="/a.png" srcset="/a-2x.png 2x, /a-3x.png 3x">
+	    <img src="/a.png">
+	    <img src="data:image/png;base64,AAAA">
+	    <picture><source srcset="/b.webp"></picture>
+	    <video poster="/poster.jpg"><source src="/clip.mp4"></video>
+	    <iframe src="https://player.example.com/embed"></iframe>
+	    <object data="/doc.pdf"></object>
+	    <div style="background-image: url(/hero.jpg)"></div>
+	  </body>
+	</html>`
+
+	res, err := parser.Parse(io.NopCloser(strings.NewReader(html)), mustURL("http://test.local/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byKind := map[string]int{}
+	third := 0
+	for _, r := range res.Resources {
+		byKind[r.Kind]++
+		if r.ThirdParty {
+			third++
+		}
+	}
+
+	want := map[string]int{
+		parser.KindStylesheet: 1,
+		parser.KindPreload:    1,
+		parser.KindIcon:       1,
+		parser.KindScript:     1,
+		parser.KindCSS:        2,
+		parser.KindImage:      5,
+		parser.KindMedia:      1,
+		parser.KindIframe:     1,
+		parser.KindObject:     1,
+	}
+	for k, n := range want {
+		if byKind[k] != n {
+			t.Errorf("expected %d %s resources, got %d", n, k, byKind[k])
+		}
+	}
+	if len(res.Resources) != 14 {
+		t.Errorf("expected 14 resources, got %d: %+v", len(res.Resources), res.Resources)
+	}
+	if third != 2 {
+		t.Errorf("expected 2 third-party resources, got %d", third)
+	}
+}
+
+func TestParse_BaseHref(t *testing.T) {
+	html := `
+	<html>
+	  <head>
+	    <base target="_blank">
+	    <base href="/docs/v2/">
+	    <base href="https://other.example/">
+	    <script src="app.js"></script>
+	  </head>
+	  <body>
+	    <a href="intro">Intro</a>
+	    <a href="/root">Root</a>
+	  </body>
+	</html>`
+
+	res, err := parser.Parse(io.NopCloser(strings.NewReader(html)), mustURL("http://test.local/page/index.html"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.BaseURL != "http://test.local/docs/v2/" {
+		t.Errorf("expected base URL from first base[href], got %q", res.BaseURL)
+	}
+	want := []string{"http://test.local/docs/v2/intro", "http://test.local/root"}
+	if len(res.Links) != len(want) {
+		t.Fatalf("expected %d links, got %v", len(want), res.Links)
+	}
+	for i := range want {
+		if res.Links[i] != want[i] {
+			t.Errorf("link %d: expected %q, got %q", i, want[i], res.Links[i])
+		}
+	}
+	if len(res.Resources) != 1 || res.Resources[0].URL != "http://test.local/docs/v2/app.js" {
+		t.Errorf("expected script resolved against base, got %+v", res.Resources)
+	}
+}
+
+func TestParse_BaseHrefIgnoresUnsafeSchemes(t *testing.T) {
+	html := `<html><head><base href="javascript:alert(1)"></head><body><a href="x">x</a></body></html>`
+
+	res, err := parser.Parse(io.NopCloser(strings.NewReader(html)), mustURL("http://test.local/a/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.BaseURL != "http://test.local/a/" {
+		t.Errorf("expected fallback to document URL, got %q", res.BaseURL)
+	}
+	if len(res.Links) != 1 || res.Links[0] != "http://test.local/a/x" {
+		t.Errorf("unexpected links: %v", res.Links)
+	}
+}
+
+func TestParse_VisibleText(t *testing.T) {
+	html := `<html><head><title>T</title><script>x()</script></head><body>
+	<h1>Hello,   world!</h1><p>Some <em>inline</em>text.<br>Next</p>
+	<div style="visibility:hidden">hidden</div><p hidden>also hidden</p>
+	<noscript>no js</noscript><section>End</section></body></html>`
+
+	p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := "Hello, world!\nSome inlinetext.\nNext\nEnd"
+	if p.Text != want {
+		t.Errorf("expected text %q, got %q", want, p.Text)
+	}
+}
+
+func TestParse_MetaRefresh(t *testing.T) {
+	cases := []struct {
+		content string
+		want    *parser.Refresh
+	}{
+		{"5", &parser.Refresh{Delay: 5}},
+		{"0;url=/next", &parser.Refresh{Target: "/next", URL: "http://example.com/next"}},
+		{` 2.9 , URL = "https://other.example/a b" `, &parser.Refresh{Delay: 2, Target: "https://other.example/a b", URL: "https://other.example/a%20b"}},
+		{"1; 'relative'", &parser.Refresh{Delay: 1, Target: "relative", URL: "http://example.com/dir/relative"}},
+		{"now; url=/x", nil},
+		{"3 url=/x", nil},
+	}
+	for _, tc := range cases {
+		html := `<html><head><meta http-equiv="refresh" content="` + strings.ReplaceAll(tc.content, `"`, "&quot;") + `"></head></html>`
+		p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/dir/page"))
+		if err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		if !reflect.DeepEqual(p.Refresh, tc.want) {
+			t.Errorf("content %q: expected %+v, got %+v", tc.content, tc.want, p.Refresh)
+		}
+	}
+}
+
+func TestParse_Images(t *testing.T) {
+	html := `<html><body>
+	  <img src="/hero.jpg" width="1200" height="600" srcset="/hero-2x.jpg 2x">
+	  <picture>
+	    <source type="image/avif" srcset="/p.avif">
+	    <img src="p.png" loading="LAZY">
+	  </picture>
+	  <picture><img src="/q.png"><source srcset="/q.webp"></picture>
+	  <img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+	</body></html>`
+	p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/dir/page"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := []parser.Image{
+		{Src: "/hero.jpg", URL: "http://example.com/hero.jpg", Width: "1200", Height: "600", Srcset: true},
+		{Src: "p.png", URL: "http://example.com/dir/p.png", Loading: "lazy", ModernSource: true},
+		// The source comes after the image, so browsers never consider it.
+		{Src: "/q.png", URL: "http://example.com/q.png"},
+		{Src: "data:image/gif;base64,", DataBytes: 42},
+	}
+	if !reflect.DeepEqual(p.Images, want) {
+		t.Errorf("expected images\n%+v\ngot\n%+v", want, p.Images)
+	}
+}
+
+func TestParse_RenderBlocking(t *testing.T) {
+	html := `<html><head>
+	  <script src="/sync.js"></script><script src="/async.js" async></script><script src="/defer.js" defer></script>
+	  <script type="module" src="/mod.js"></script><script type="application/ld+json" src="/data.json"></script>
+	  <link rel="stylesheet" href="/all.css"><link rel="stylesheet" href="https://cdn.test/print.css" media="print">
+	  <link rel="stylesheet" href="https://cdn.test/screen.css" media="Screen">
+	</head><body><script src="/body.js"></script></body></html>`
+	p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := []parser.Resource{
+		{Kind: parser.KindScript, Tag: "script", URL: "http://example.com/sync.js"},
+		{Kind: parser.KindStylesheet, Tag: "link", URL: "http://example.com/all.css"},
+		{Kind: parser.KindStylesheet, Tag: "link", URL: "https://cdn.test/screen.css", ThirdParty: true},
+	}
+	if !reflect.DeepEqual(p.RenderBlocking, want) {
+		t.Errorf("expected render-blocking resources\n%+v\ngot\n%+v", want, p.RenderBlocking)
+	}
+}
+
+func TestParse_Attributes(t *testing.T) {
+	html := `<html><head><meta name="description" content="Call us"></head><body>
+	  <a href="tel:+15550100" class="phone" data-contact=" sales ">Call</a>
+	  <img src="/a.png" alt="">
+	  <a href="mailto:sales@example.com">Mail</a>
+	</body></html>`
+	p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := []parser.AttrValue{
+		{Tag: "meta", Index: 1, Name: "content", Value: "Call us"},
+		{Tag: "a", Index: 1, Name: "href", Value: "tel:+15550100"},
+		{Tag: "a", Index: 1, Name: "data-contact", Value: " sales "},
+		{Tag: "a", Index: 2, Name: "href", Value: "mailto:sales@example.com"},
+	}
+	if !reflect.DeepEqual(p.Attributes, want) {
+		t.Errorf("expected attributes\n%+v\ngot\n%+v", want, p.Attributes)
+	}
+}
+
+func TestParse_InlineCode(t *testing.T) {
+	html := `<html><body onload="init()">
+	  <a href=" JavaScript:void(0)" onclick="go()">Go</a><a href="/javascript:ok">Ok</a>
+	  <div style="display:flex"></div>
+	  <script src="https://cdn.test/lib.js" integrity="sha384-abc"></script><script src="https://cdn.test/x.js"></script>
+	</body></html>`
+	p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := parser.InlineCode{
+		Handlers: []parser.AttrValue{{Tag: "body", Name: "onload", Value: "init()"}, {Tag: "a", Name: "onclick", Value: "go()"}},
+		JSURLs:   []parser.AttrValue{{Tag: "a", Name: "href", Value: " JavaScript:void(0)"}},
+		Styles:   []parser.AttrValue{{Tag: "div", Name: "style", Value: "display:flex"}},
+	}
+	if !reflect.DeepEqual(p.InlineCode, want) {
+		t.Errorf("expected inline code\n%+v\ngot\n%+v", want, p.InlineCode)
+	}
+
+	integrity := map[string]bool{}
+	for _, r := range p.Resources {
+		integrity[r.URL] = r.Integrity
+	}
+	if !integrity["https://cdn.test/lib.js"] || integrity["https://cdn.test/x.js"] {
+		t.Errorf("unexpected integrity flags %v", integrity)
+	}
+}
+
+func TestParse_FeedsAndManifest(t *testing.T) {
+	html := `<html><head>
+	  <link rel="alternate" type="application/rss+xml" title="News" href="/rss">
+	  <link rel="alternate" type="text/html" href="/amp">
+	  <link rel="manifest" href="https://cdn.test/site.webmanifest">
+	</head></html>`
+	p, err := parser.Parse(strings.NewReader(html), mustURL("http://example.com/blog/"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := []parser.Feed{{Type: "application/rss+xml", Title: "News", Href: "/rss", URL: "http://example.com/rss"}}
+	if !reflect.DeepEqual(p.Feeds, want) {
+		t.Errorf("expected feeds %+v, got %+v", want, p.Feeds)
+	}
+	if p.Manifest != "https://cdn.test/site.webmanifest" {
+		t.Errorf("unexpected manifest %q", p.Manifest)
+	}
+}
+
+func TestParse_AMPAndRelations(t *testing.T) {
+	html := `<html amp><head>
+	  <link rel="canonical" href="https://example.com/story">
+	  <link rel="next" href="page/3"><link rel="prev" href="page/1">
+	</head></html>`
+	p, err := parser.Parse(strings.NewReader(html), mustURL("https://example.com/amp/story/"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	if !p.AMP {
+		t.Error("expected an AMP document")
+	}
+	want := parser.Relations{
+		Canonical: "https://example.com/story",
+		Next:      "https://example.com/amp/story/page/3",
+		Prev:      "https://example.com/amp/story/page/1",
+	}
+	if p.Relations != want {
+		t.Errorf("expected relations %+v, got %+v", want, p.Relations)
+	}
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Refresh is a <meta http-equiv="refresh"> instruction.
+type Refresh struct {
+	// Delay is in seconds.
+	Delay float64
+	// Target is the url as written, empty when the page reloads itself. URL
+	// is Target resolved against the document base, or empty when it
+	// cannot be resolved.
+	Target string
+	URL    string
+}
+
+// refreshMeta parses a meta refresh element the way browsers do, e.g.
+// content="5; url=/next". Malformed values are ignored by browsers, and so
+// here.
+func refreshMeta(attrs []html.Attribute) (*Refresh, bool) {
+	equiv, _ := attr(attrs, "http-equiv")
+	content, ok := attr(attrs, "content")
+	if !ok || !strings.EqualFold(strings.TrimSpace(equiv), "refresh") {
+		return nil, false
+	}
+
+	const ws = " \t\n\f\r"
+	s := strings.TrimLeft(content, ws)
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	if i == 0 {
+		return nil, false
+	}
+	digits := s[:i]
+	if dot := strings.IndexByte(digits, '.'); dot >= 0 {
+		digits = digits[:dot]
+	}
+	if digits == "" {
+		digits = "0"
+	}
+	delay, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	s = strings.TrimLeft(s[i:], ws)
+	if s == "" {
+		return &Refresh{Delay: delay}, true
+	}
+	if s[0] != ';' && s[0] != ',' {
+		return nil, false
+	}
+	s = strings.TrimLeft(s[1:], ws)
+	if len(s) >= 3 && strings.EqualFold(s[:3], "url") {
+		rest := strings.TrimLeft(s[3:], ws)
+		if strings.HasPrefix(rest, "=") {
+			s = strings.TrimLeft(rest[1:], ws)
+		}
+	}
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+			s = s[1 : end+1]
+		} else {
+			s = s[1:]
+		}
+	}
+	return &Refresh{Delay: delay, Target: strings.TrimSpace(s)}, true
+}
+
+func resolveRefresh(r *Refresh, base *url.URL) *Refresh {
+	if r == nil || r.Target == "" {
+		return r
+	}
+	if u, err := base.Parse(r.Target); err == nil && u.Host != "" {
+		r.URL = u.String()
+	}
+	return r
+}
+
+// collectRefresh returns the first valid meta refresh, which is the one
+// browsers act on.
+func collectRefresh(doc *goquery.Document, base *url.URL) *Refresh {
+	var found *Refresh
+	doc.Find("meta[http-equiv]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if r, ok := refreshMeta(s.Nodes[0].Attr); ok {
+			found = r
+			return false
+		}
+		return true
+	})
+	return resolveRefresh(found, base)
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Relations are the page relationships declared with <link rel>: the
+// canonical URL, the AMP counterpart and the pagination neighbours. Each is
+// the first such link, resolved against the document base, or empty.
+type Relations struct {
+	Canonical string
+	AMPHTML   string
+	Next      string
+	Prev      string
+}
+
+// observe records the relations a <link> declares, keeping the first of
+// each. Hrefs stay raw until resolve.
+func (r *Relations) observe(attrs []html.Attribute) {
+	rel, _ := attr(attrs, "rel")
+	href, _ := attr(attrs, "href")
+	if href = strings.TrimSpace(href); href == "" {
+		return
+	}
+	set := func(field *string, tokens ...string) {
+		for _, t := range tokens {
+			if *field == "" && hasToken(rel, t) {
+				*field = href
+			}
+		}
+	}
+	set(&r.Canonical, "canonical")
+	set(&r.AMPHTML, "amphtml")
+	set(&r.Next, "next")
+	set(&r.Prev, "prev", "previous")
+}
+
+func (r Relations) resolve(base *url.URL) Relations {
+	return Relations{
+		Canonical: resolveHref(base, r.Canonical),
+		AMPHTML:   resolveHref(base, r.AMPHTML),
+		Next:      resolveHref(base, r.Next),
+		Prev:      resolveHref(base, r.Prev),
+	}
+}
+
+// ampAttr reports whether the attributes of the root <html> element mark
+// the document as AMP.
+func ampAttr(attrs []html.Attribute) bool {
+	for _, a := range attrs {
+		switch strings.ToLower(a.Key) {
+		case "amp", "⚡":
+			return true
+		}
+	}
+	return false
+}
+
+func collectRelations(doc *goquery.Document, base *url.URL) Relations {
+	var r Relations
+	doc.Find("link").Each(func(_ int, s *goquery.Selection) {
+		r.observe(s.Nodes[0].Attr)
+	})
+	return r.resolve(base)
+}
+
+func documentAMP(doc *goquery.Document) bool {
+	if n := doc.Find("html").First().Nodes; len(n) > 0 {
+		return ampAttr(n[0].Attr)
+	}
+	return false
+}
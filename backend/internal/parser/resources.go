@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Resource kinds reported in Parsed.Resources.
+const (
+	KindScript     = "script"
+	KindStylesheet = "stylesheet"
+	KindPreload    = "preload"
+	KindIcon       = "icon"
+	KindImage      = "image"
+	KindMedia      = "media"
+	KindIframe     = "iframe"
+	KindObject     = "object"
+	KindCSS        = "css"
+)
+
+// Resource is a subresource referenced by the document.
+type Resource struct {
+	Kind       string
+	Tag        string
+	URL        string
+	ThirdParty bool
+	// Integrity reports whether a script or <link> carries a Subresource
+	// Integrity hash.
+	Integrity bool
+}
+
+var cssURLRe = regexp.MustCompile(`url\(\s*['"]?([^'"()\s]+)['"]?\s*\)`)
+
+// resourceRef is a raw, unresolved reference found on a single element.
+type resourceRef struct {
+	kind      string
+	raw       string
+	integrity bool
+}
+
+// elementResources returns the subresource references carried by one element.
+// inMedia reports whether the element sits inside <video> or <audio>, which
+// decides whether a <source> points at media or at an image candidate.
+func elementResources(tag string, attrs []html.Attribute, inMedia bool) []resourceRef {
+	get := func(key string) string {
+		v, _ := attr(attrs, key)
+		return strings.TrimSpace(v)
+	}
+
+	var refs []resourceRef
+	add := func(kind, raw string) {
+		if kind != "" && raw != "" {
+			refs = append(refs, resourceRef{kind: kind, raw: raw})
+		}
+	}
+
+	switch tag {
+	case "script":
+		add(KindScript, get("src"))
+	case "link":
+		add(linkKind(get("rel")), get("href"))
+	case "img":
+		add(KindImage, get("src"))
+		for _, c := range srcsetURLs(get("srcset")) {
+			add(KindImage, c)
+		}
+	case "source":
+		if inMedia {
+			add(KindMedia, get("src"))
+		} else {
+			add(KindImage, get("src"))
+		}
+		for _, c := range srcsetURLs(get("srcset")) {
+			add(KindImage, c)
+		}
+	case "video", "audio":
+		add(KindMedia, get("src"))
+		add(KindImage, get("poster"))
+	case "iframe":
+		add(KindIframe, get("src"))
+	case "object":
+		add(KindObject, get("data"))
+	}
+	if (tag == "script" || tag == "link") && get("integrity") != "" {
+		for i := range refs {
+			refs[i].integrity = true
+		}
+	}
+
+	if style := get("style"); style != "" {
+		refs = append(refs, cssRefs(style)...)
+	}
+	return refs
+}
+
+// linkKind maps a <link rel> value to a resource kind, or "" when the link
+// does not load a subresource.
+func linkKind(rel string) string {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		switch r {
+		case "stylesheet":
+			return KindStylesheet
+		case "preload", "modulepreload":
+			return KindPreload
+		case "icon", "apple-touch-icon", "mask-icon":
+			return KindIcon
+		}
+	}
+	return ""
+}
+
+// srcsetURLs returns the candidate URLs of a srcset attribute.
+func srcsetURLs(v string) []string {
+	if v == "" {
+		return nil
+	}
+	var out []string
+	for _, cand := range strings.Split(v, ",") {
+		if f := strings.Fields(cand); len(f) > 0 {
+			out = append(out, f[0])
+		}
+	}
+	return out
+}
+
+// cssRefs returns the url() references in a CSS fragment.
+func cssRefs(css string) []resourceRef {
+	var out []resourceRef
+	for _, m := range cssURLRe.FindAllStringSubmatch(css, -1) {
+		out = append(out, resourceRef{kind: KindCSS, raw: m[1]})
+	}
+	return out
+}
+
+// resourceSet resolves references against the document base and
+// de-duplicates them by kind and URL. Party classification is relative to the
+// document URL, not the base.
+type resourceSet struct {
+	base   *url.URL
+	docURL *url.URL
+	seen   map[string]bool
+	list   []Resource
+}
+
+func newResourceSet(base, docURL *url.URL) *resourceSet {
+	return &resourceSet{base: base, docURL: docURL, seen: map[string]bool{}}
+}
+
+func (s *resourceSet) add(tag string, refs []resourceRef) {
+	for _, r := range refs {
+		u, err := s.base.Parse(r.raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		u.Fragment = ""
+		key := r.kind + " " + u.String()
+		if s.seen[key] {
+			continue
+		}
+		s.seen[key] = true
+		s.list = append(s.list, Resource{
+			Kind:       r.kind,
+			Tag:        tag,
+			URL:        u.String(),
+			ThirdParty: !strings.EqualFold(u.Host, s.docURL.Host),
+			Integrity:  r.integrity,
+		})
+	}
+}
+
+func collectResources(root *html.Node, base, docURL *url.URL) []Resource {
+	set := newResourceSet(base, docURL)
+	var walk func(n *html.Node, inMedia bool)
+	walk = func(n *html.Node, inMedia bool) {
+		if n.Type == html.ElementNode {
+			tag := strings.ToLower(n.Data)
+			set.add(tag, elementResources(tag, n.Attr, inMedia))
+			if tag == "style" {
+				set.add(tag, cssRefs(nodeText(n)))
+			}
+			if tag == "video" || tag == "audio" {
+				inMedia = true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inMedia)
+		}
+	}
+	walk(root, false)
+	return set.list
+}
+
+// nodeText concatenates the text node children of n.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return b.String()
+}
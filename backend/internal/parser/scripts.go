@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// InlineScript is a <script> element without a src attribute.
+type InlineScript struct {
+	// Type is the lower-cased type attribute, empty for classic scripts.
+	Type    string
+	Content string
+}
+
+// inlineScriptType reports whether a script element is inline and returns
+// its type.
+func inlineScriptType(attrs []html.Attribute) (string, bool) {
+	if _, ok := attr(attrs, "src"); ok {
+		return "", false
+	}
+	typ, _ := attr(attrs, "type")
+	return strings.ToLower(strings.TrimSpace(typ)), true
+}
+
+func collectInlineScripts(doc *goquery.Document) []InlineScript {
+	var out []InlineScript
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		n := s.Nodes[0]
+		if typ, ok := inlineScriptType(n.Attr); ok {
+			out = append(out, InlineScript{Type: typ, Content: nodeText(n)})
+		}
+	})
+	return out
+}
@@ -0,0 +1,514 @@
+package parser
+
+import (
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxStreamText caps the text buffered for a single title or call-to-action
+// element, so a pathological document cannot grow the parser's state.
+const maxStreamText = 4096
+
+// ParseStream produces the same Parsed output as Parse in a single pass over
+// an html.Tokenizer, without building a node tree. It saves the memory of the
+// tree, but the visible text, inline style and script contents, links and
+// resources it collects still grow with the document; only title and
+// call-to-action text are capped, at maxStreamText. Callers bound the rest
+// by capping the body, as fetch.Client does. Parsed.Doc is left nil.
+func ParseStream(r io.Reader, docURL *url.URL) (*Parsed, error) {
+	z := html.NewTokenizer(r)
+	st := &streamState{
+		headings:  headingCounts(),
+		tagCounts: map[string]int{},
+	}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				slog.Error("failed to parse HTML", "base_url", docURL.String(), "err", err)
+				return nil, err
+			}
+			return st.finish(docURL), nil
+		case html.DoctypeToken:
+			if !st.started && !st.sawDoctype {
+				st.sawDoctype = true
+				st.doctype = newDoctype(parseDoctypeToken(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			st.started = true
+			tok := z.Token()
+			st.startTag(strings.ToLower(tok.Data), tok.Attr, tt == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			st.endTag(strings.ToLower(string(name)))
+		case html.TextToken:
+			st.text(z.Text())
+		}
+	}
+}
+
+// streamState is the incremental state of ParseStream. URLs are kept raw
+// until the end of the document, because <base href> applies to the whole
+// document regardless of where it appears.
+type streamState struct {
+	started    bool
+	sawDoctype bool
+	doctype    *Doctype
+
+	title     strings.Builder
+	inTitle   bool
+	titleDone bool
+
+	headings map[string]int
+	lang     string
+	sawHTML  bool
+
+	hrefs     []string
+	baseHref  string
+	sawBase   bool
+	resources []pendingResources
+	alts      []Alternate
+	refresh   *Refresh
+	images    []Image
+	blocking  []pendingResources
+	attrs     []AttrValue
+	tagCounts map[string]int
+	inline    InlineCode
+	feeds     []Feed
+	manifest  string
+	amp       bool
+	relations Relations
+	// pictures holds, for each open <picture>, whether it has offered a
+	// modern source so far.
+	pictures []bool
+
+	// inBody is set once the tree builder would have closed <head>.
+	// headRaw is the open head element whose content is text, and
+	// headTemplate counts open <template> elements in the head, whose
+	// content does not close it.
+	inBody       bool
+	headRaw      string
+	headTemplate int
+
+	visible textBuffer
+	// skipTag is the hidden element being skipped. skipNest counts open
+	// elements of the same name; skipScope counts the open scope elements
+	// (see impliedEnds) inside one whose end tag may be omitted.
+	skipTag   string
+	skipNest  int
+	skipScope int
+
+	mediaDepth int
+	inStyle    bool
+	style      strings.Builder
+	inScript   bool
+	scriptType string
+	script     strings.Builder
+	scripts    []InlineScript
+
+	form     *formInfo
+	forms    []*formInfo
+	ctas     []*ctaText
+	ctaCands []LoginCandidate
+}
+
+type pendingResources struct {
+	tag  string
+	refs []resourceRef
+}
+
+// ctaText is an open <a> or <button> whose text is being collected.
+type ctaText struct {
+	tag    string
+	attrs  []html.Attribute
+	inForm bool
+	text   strings.Builder
+}
+
+func (st *streamState) startTag(tag string, attrs []html.Attribute, selfClosing bool) {
+	st.enterVisible(tag, attrs)
+	st.enterHead(tag, selfClosing)
+	st.tagCounts[tag]++
+	st.attrs = appendAttrValues(st.attrs, tag, st.tagCounts[tag], attrs)
+	st.inline.observe(tag, attrs)
+	if ref, ok := blockingRef(tag, attrs, !st.inBody); ok {
+		st.blocking = append(st.blocking, pendingResources{tag: tag, refs: []resourceRef{ref}})
+	}
+
+	switch {
+	case tag == "title":
+		if !st.titleDone && !selfClosing {
+			st.inTitle = true
+		}
+	case isHeading(tag):
+		st.headings[tag]++
+	case tag == "html":
+		if !st.sawHTML {
+			st.sawHTML = true
+			v, _ := attr(attrs, "lang")
+			st.lang = strings.TrimSpace(v)
+			st.amp = ampAttr(attrs)
+		}
+	case tag == "link":
+		if a, ok := alternateRef(attrs); ok {
+			st.alts = append(st.alts, a)
+		}
+		if f, ok := feedRef(attrs); ok {
+			st.feeds = append(st.feeds, f)
+		}
+		if m, ok := manifestRef(attrs); ok && st.manifest == "" {
+			st.manifest = m
+		}
+		st.relations.observe(attrs)
+	case tag == "meta":
+		if r, ok := refreshMeta(attrs); ok && st.refresh == nil {
+			st.refresh = r
+		}
+	case tag == "base":
+		if v, ok := attr(attrs, "href"); ok && !st.sawBase {
+			st.sawBase = true
+			st.baseHref = v
+		}
+	case tag == "style":
+		st.inStyle = !selfClosing
+		st.style.Reset()
+	case tag == "script":
+		if typ, ok := inlineScriptType(attrs); ok && !selfClosing {
+			st.inScript = true
+			st.scriptType = typ
+			st.script.Reset()
+		}
+	case tag == "picture":
+		if !selfClosing {
+			st.pictures = append(st.pictures, false)
+		}
+	case tag == "source":
+		if n := len(st.pictures); n > 0 && modernSource(attrs) {
+			st.pictures[n-1] = true
+		}
+	case tag == "img":
+		n := len(st.pictures)
+		st.images = append(st.images, imageRef(attrs, n > 0 && st.pictures[n-1]))
+	case tag == "form":
+		if st.form == nil && !selfClosing {
+			st.form = newFormInfo(attrs)
+		}
+	}
+
+	if tag == "a" {
+		if v, ok := attr(attrs, "href"); ok && v != "" {
+			st.hrefs = append(st.hrefs, v)
+		}
+	}
+	if refs := elementResources(tag, attrs, st.mediaDepth > 0); len(refs) > 0 {
+		st.resources = append(st.resources, pendingResources{tag: tag, refs: refs})
+	}
+	if (tag == "video" || tag == "audio") && !selfClosing {
+		st.mediaDepth++
+	}
+	if st.form != nil {
+		st.form.observe(tag, attrs)
+	}
+	if (tag == "a" || tag == "button") && !selfClosing {
+		st.ctas = append(st.ctas, &ctaText{tag: tag, attrs: attrs, inForm: st.form != nil})
+	}
+}
+
+func (st *streamState) endTag(tag string) {
+	st.leaveVisible(tag)
+	switch {
+	case tag == st.headRaw:
+		st.headRaw = ""
+	case tag == "template" && st.headTemplate > 0:
+		st.headTemplate--
+	}
+
+	switch tag {
+	case "title":
+		if st.inTitle {
+			st.inTitle = false
+			st.titleDone = true
+		}
+	case "style":
+		if st.inStyle {
+			st.inStyle = false
+			if refs := cssRefs(st.style.String()); len(refs) > 0 {
+				st.resources = append(st.resources, pendingResources{tag: tag, refs: refs})
+			}
+		}
+	case "script":
+		if st.inScript {
+			st.inScript = false
+			st.scripts = append(st.scripts, InlineScript{Type: st.scriptType, Content: st.script.String()})
+		}
+	case "video", "audio":
+		if st.mediaDepth > 0 {
+			st.mediaDepth--
+		}
+	case "picture":
+		if n := len(st.pictures); n > 0 {
+			st.pictures = st.pictures[:n-1]
+		}
+	case "form":
+		st.closeForm()
+	case "a", "button":
+		for i := len(st.ctas) - 1; i >= 0; i-- {
+			if st.ctas[i].tag == tag {
+				st.closeCTA(st.ctas[i])
+				st.ctas = append(st.ctas[:i], st.ctas[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (st *streamState) text(b []byte) {
+	if !st.inBody && st.headRaw == "" && st.headTemplate == 0 && strings.Trim(string(b), " \t\n\f\r") != "" {
+		st.inBody = true
+	}
+	if st.inTitle {
+		appendCapped(&st.title, b)
+	}
+	if st.inStyle {
+		st.style.Write(b)
+	}
+	if st.inScript {
+		st.script.Write(b)
+	}
+	for _, c := range st.ctas {
+		appendCapped(&c.text, b)
+	}
+	if st.skipTag == "" {
+		st.visible.write(string(b))
+	}
+}
+
+// enterVisible tracks the hidden element, if any, whose content is left out
+// of the visible text. Without a tree only the outermost one is tracked, by
+// counting nested elements of the same name, or for an element whose end tag
+// may be omitted, by watching for the tags that close it.
+func (st *streamState) enterVisible(tag string, attrs []html.Attribute) {
+	if st.skipTag == "" {
+		st.visible.boundary(tag)
+		if !voidTags[tag] && hiddenElement(tag, attrs) {
+			st.skipTag, st.skipNest, st.skipScope = tag, 1, 0
+		}
+		return
+	}
+	e, ok := impliedEnds[st.skipTag]
+	switch {
+	case ok && st.skipScope == 0 && e.closedBy[tag]:
+		// <p hidden>a<div>b: the block closes the hidden paragraph.
+		st.skipTag = ""
+		st.enterVisible(tag, attrs)
+	case ok:
+		if e.scope[tag] {
+			st.skipScope++
+		}
+	case tag == st.skipTag:
+		st.skipNest++
+	}
+}
+
+func (st *streamState) leaveVisible(tag string) {
+	if st.skipTag == "" {
+		st.visible.boundary(tag)
+		return
+	}
+	e, ok := impliedEnds[st.skipTag]
+	switch {
+	case ok && st.skipScope > 0:
+		if e.scope[tag] {
+			st.skipScope--
+		}
+	case ok && tag == st.skipTag:
+		st.skipTag = ""
+	case ok && (e.scope[tag] || st.skipTag == "p" && closesP[tag]):
+		// <ul><li hidden>a</ul>: closing the parent closes the hidden item.
+		st.skipTag = ""
+		st.visible.boundary(tag)
+	case tag == st.skipTag:
+		if st.skipNest--; st.skipNest == 0 {
+			st.skipTag = ""
+		}
+	}
+}
+
+// enterHead tracks where the tree builder would close <head> and start the
+// body.
+func (st *streamState) enterHead(tag string, selfClosing bool) {
+	switch {
+	case st.inBody || st.headRaw != "" || tag == "html" || tag == "head":
+	case st.headTemplate > 0:
+		if tag == "template" && !selfClosing {
+			st.headTemplate++
+		}
+	case tag == "template":
+		if !selfClosing {
+			st.headTemplate++
+		}
+	case headElements[tag]:
+		if headRawText[tag] && !selfClosing {
+			st.headRaw = tag
+		}
+	default:
+		st.inBody = true
+	}
+}
+
+func (st *streamState) closeCTA(c *ctaText) {
+	text := c.text.String()
+	if c.tag == "button" && c.inForm {
+		if st.form != nil {
+			st.form.observeButtonText(text)
+		}
+		return
+	}
+	if cand := ctaCandidate(c.tag, c.attrs, text); cand != nil {
+		st.ctaCands = append(st.ctaCands, *cand)
+	}
+}
+
+func (st *streamState) closeForm() {
+	if st.form != nil {
+		st.forms = append(st.forms, st.form)
+	}
+	st.form = nil
+}
+
+func (st *streamState) finish(docURL *url.URL) *Parsed {
+	// Elements left open at EOF are closed implicitly, as the tree builder does.
+	if st.inTitle {
+		st.titleDone = true
+	}
+	if st.inStyle {
+		st.endTag("style")
+	}
+	if st.inScript {
+		st.endTag("script")
+	}
+	for i := len(st.ctas) - 1; i >= 0; i-- {
+		st.closeCTA(st.ctas[i])
+	}
+	st.closeForm()
+	var formCands []LoginCandidate
+	for _, f := range st.forms {
+		if c := f.candidate(); c != nil {
+			formCands = append(formCands, *c)
+		}
+	}
+	login := rankLoginCandidates(formCands, st.ctaCands)
+
+	base := docURL
+	if st.sawBase {
+		base = resolveBase(docURL, st.baseHref)
+	}
+
+	var links []string
+	for _, href := range st.hrefs {
+		if u, ok := resolveLink(base, href); ok {
+			links = append(links, u)
+		}
+	}
+
+	set := newResourceSet(base, docURL)
+	for _, p := range st.resources {
+		set.add(p.tag, p.refs)
+	}
+	blocking := newResourceSet(base, docURL)
+	for _, p := range st.blocking {
+		blocking.add(p.tag, p.refs)
+	}
+
+	parsed := &Parsed{
+		HTMLVersion:      st.doctype.version(),
+		BaseURL:          base.String(),
+		Title:            strings.TrimSpace(st.title.String()),
+		Lang:             st.lang,
+		Headings:         st.headings,
+		Links:            links,
+		Resources:        set.list,
+		Images:           resolveImages(st.images, base),
+		RenderBlocking:   blocking.list,
+		Forms:            resolveForms(st.forms, base, docURL),
+		Alternates:       resolveAlternates(st.alts, base),
+		Doctype:          st.doctype,
+		Refresh:          resolveRefresh(st.refresh, base),
+		Attributes:       st.attrs,
+		InlineCode:       st.inline,
+		Feeds:            resolveFeeds(st.feeds, base),
+		Manifest:         resolveHref(base, st.manifest),
+		AMP:              st.amp,
+		Relations:        st.relations.resolve(base),
+		Text:             st.visible.String(),
+		InlineScripts:    st.scripts,
+		LoginFormPresent: login.Present,
+		Login:            login,
+	}
+
+	slog.Debug("stream-parsed HTML successfully",
+		"base_url", base.String(),
+		"document_url", docURL.String(),
+		"title", parsed.Title,
+		"links_total", len(parsed.Links),
+		"resources_total", len(parsed.Resources),
+		"login_form_present", parsed.LoginFormPresent,
+	)
+	return parsed
+}
+
+func appendCapped(b *strings.Builder, p []byte) {
+	if room := maxStreamText - b.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		b.Write(p)
+	}
+}
+
+// parseDoctypeToken splits the text of a doctype token into its name and
+// public/system identifiers, the way the tree builder records them.
+func parseDoctypeToken(s string) (string, []html.Attribute) {
+	const ws = " \t\n\f\r"
+	s = strings.TrimLeft(s, ws)
+	end := strings.IndexAny(s, ws)
+	if end == -1 {
+		end = len(s)
+	}
+	name := strings.ToLower(s[:end])
+	s = strings.TrimLeft(s[end:], ws)
+	if len(s) < 6 {
+		return name, nil
+	}
+
+	var ids []html.Attribute
+	key := strings.ToLower(s[:6])
+	s = s[6:]
+	for key == "public" || key == "system" {
+		s = strings.TrimLeft(s, ws)
+		if s == "" || (s[0] != '"' && s[0] != '\'') {
+			break
+		}
+		quote := s[0]
+		s = s[1:]
+		id := s
+		if q := strings.IndexByte(s, quote); q != -1 {
+			id, s = s[:q], s[q+1:]
+		} else {
+			s = ""
+		}
+		ids = append(ids, html.Attribute{Key: key, Val: id})
+		if key == "public" {
+			key = "system"
+		} else {
+			key = ""
+		}
+	}
+	return name, ids
+}
@@ -0,0 +1,230 @@
+package parser_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+var parityFixtures = map[string]string{
+	"basic": `
+	<!DOCTYPE html>
+	<html>
+	  <head>
+	    <title>  My Test Page </title>
+	    <link rel="stylesheet" href="/main.css">
+	    <style>.hero { background: url("/hero.png") }</style>
+	  </head>
+	  <body>
+	    <h1>Main Heading</h1>
+	    <h2>Sub</h2><h2>Sub</h2>
+	    <a href="/internal">Internal Link</a>
+	    <a href="https://example.com">External Link</a>
+	    <a href="">empty</a>
+	    <a href="mailto:x@example.com">mail</a>
+	    <img src="/a.png" srcset="/a-2x.png 2x">
+	    <video src="/v.mp4"><source src="/v.webm"></video>
+	    <picture><source srcset="/p.webp"></picture>
+	  </body>
+	</html>`,
+	"xhtml": `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+	<html><head><title>Legacy</title></head><body><h3>x</h3></body></html>`,
+	"html401": `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN">
+	<html><head><title>Frames</title></head></html>`,
+	"no-doctype": `<html><body><h1>No doctype</h1></body></html>`,
+	"base-late": `
+	<html><head><title>Base</title></head>
+	<body>
+	  <a href="one">one</a>
+	  <script src="app.js"></script>
+	  <base href="https://cdn.test.local/assets/">
+	</body></html>`,
+	"login-form": `
+	<html><body>
+	  <form action="/session">
+	    <input name="username"><button type="submit">Go</button>
+	  </form>
+	</body></html>`,
+	"password-form": `<html><body><form><input type="password" /></form></body></html>`,
+	"auth-cta":      `<html><body><nav><a href="/x"><span>Sign</span> in</a></nav></body></html>`,
+	"no-login":      `<html><body><form><input name="q"><button>Search</button></form><a href="/a">About</a></body></html>`,
+	"signup-and-sso": `
+	<html><body>
+	  <header><a class="nav" href="/account">My account</a><button>Sign in</button></header>
+	  <form id="register" action="/users">
+	    <input type="email" name="email" autocomplete="email">
+	    <input type="password" autocomplete="new-password">
+	    <input type="password" autocomplete="new-password">
+	    <button type="submit">Create account</button>
+	  </form>
+	  <a href="https://accounts.google.com/o/oauth2/auth">Continue with Google</a>
+	</body></html>`,
+	"forms": `
+	<html><head><base href="/app/"></head><body>
+	  <form method="post" action="save" autocomplete="off">
+	    <input type="hidden" name="csrf_token"><input name="title"><textarea name="body"></textarea>
+	    <select name="tag"><option>a</option></select>
+	  </form>
+	  <form action=""><input type="search" name="q"></form>
+	</body></html>`,
+	"hreflang": `
+	<html lang="en-GB"><head>
+	  <link rel="alternate" hreflang="en-gb" href="/en-gb/">
+	  <link rel="alternate" hreflang="de" href="https://test.local/de/">
+	  <link rel="alternate" hreflang="x-default" href="/">
+	  <link rel="alternate" type="application/rss+xml" href="/feed">
+	  <link rel="canonical alternate" hreflang="fr" href="">
+	</head></html>`,
+	"visible-text": `
+	<html><head><title>Hidden title</title><style>p { color: red }</style></head>
+	<body>
+	  <p>First <b>bold</b>word&amp;more.</p><div>Second</div>
+	  <script>var x = "no";</script><noscript>Enable JS</noscript>
+	  <div hidden><p>secret</p><div>nested <div>deep</div></div></div>
+	  <span style="display: none">gone</span><span aria-hidden="true">icon</span>
+	  <ul><li hidden>skip<li>keep</ul>
+	  <template><p>tpl</p></template><img hidden alt="x">Tail
+	</body></html>`,
+	"inline-scripts": `
+	<html><head>
+	  <script>/*! jQuery v3.4.1 | (c) JS Foundation */ var a = "&amp;";</script>
+	  <script src="/app.js"></script>
+	  <script type="application/ld+json">{"@type": "Organization"}</script>
+	</head><body><script type="Module">import x from "./x.js"</script><script>if (a < b) {}`,
+	"meta-refresh": `
+	<html><head><base href="https://cdn.test.local/"><meta http-equiv="refresh" content="soon">
+	<meta http-equiv="Refresh" content="3; URL='next/page.html'">
+	<meta http-equiv="refresh" content="0; url=/ignored">
+	</head></html>`,
+	"images": `
+	<html><head><base href="https://cdn.test.local/img/"></head><body>
+	  <img src="a.jpg" width="10" height="10" loading="lazy"><img srcset="b.png 1x">
+	  <picture><source type="image/webp" srcset="c.webp"><div><img src="c.jpg"></div></picture>
+	  <picture><source srcset="d.avif 2x, d.png 1x"></picture><img src="e.gif">
+	  <img src="DATA:image/png;base64,iVBORw0KGgo=">
+	</body></html>`,
+	"render-blocking": `
+	<html><head>
+	  <title>Blocking <script src="/not-a-script.js"></script></title>
+	  <script src="/sync.js"></script><script src="/async.js" async></script>
+	  <script type="module" src="/mod.js"></script><script src="/defer.js" defer></script>
+	  <link rel="stylesheet" href="/all.css"><link rel="stylesheet" href="/print.css" media="print">
+	  <link rel="alternate stylesheet" href="/alt.css"><noscript><link rel="stylesheet" href="/ns.css"></noscript>
+	  <template><p>tpl</p></template><script src="/after-template.js"></script>
+	</head>
+	<script src="/after-head.js"></script>
+	<body><script src="/body.js"></script><link rel="stylesheet" href="/late.css" media="screen"></body></html>`,
+	"attributes": `
+	<html><head><meta name="author" content="Jo Doe, jo@example.com"></head><body>
+	  <a href="mailto:jo@example.com" title="Mail us" data-phone="+1 555 0100">Mail</a>
+	  <input placeholder="Card number" value=" " ARIA-LABEL="Payment">
+	  <p>Or call <a href="tel:+15550100">us</a>.</p>
+	</body></html>`,
+	"inline-code": `
+	<html><body onload="init()">
+	  <a href=" java	script:void(0)" onClick="go()" style="color:red">Go</a>
+	  <form action="javascript:submit()"><button formaction="/ok" style=" ">Send</button></form>
+	  <script src="https://cdn.test/lib.js" integrity="sha384-abc" crossorigin="anonymous"></script>
+	  <link rel="stylesheet" href="/site.css" integrity="sha384-def">
+	</body></html>`,
+	"site-links": `
+	<html><head><base href="https://blog.test.local/">
+	  <link rel="alternate" type="application/rss+xml" title=" Posts " href="/feed.xml">
+	  <link rel="alternate" type="Application/Atom+XML" href="atom.xml"><link rel="alternate" type="text/html" href="/print">
+	  <link rel="manifest" href="/app.webmanifest"><link rel="manifest" href="/second.json">
+	</head><body><link rel="alternate" type="application/feed+json" href="feed.json"></body></html>`,
+	"amp-pagination": `
+	<!doctype html><html ⚡ lang="en"><head>
+	  <link rel="canonical" href="/article"><link rel="canonical" href="/other">
+	  <link rel="amphtml" href="">
+	  <link rel="previous" href="?page=1"><link rel="next" href="?page=3"><link rel="prev" href="?page=0">
+	</head><body></body></html>`,
+	"hidden-implied-end": `
+	<html><body>
+	  <p hidden>secret<div>Visible body text here</div><h2>Heading</h2>
+	  <ul><li hidden>gone<ul><li>nested</li></ul><li>Shown item</li></ul>
+	  <ul><li aria-hidden="true">gone</ul><p>After list</p>
+	  <dl><dt hidden>term<dd>Definition</dl>
+	  <div><p style="display:none">hidden<button><div>still hidden</div></button></div>After div
+	  <select><option hidden>gone<option>Choice</select>
+	</body></html>`,
+	"implicit-body": `<html><head><script src="/a.js"></script>Text<script src="/b.js"></script>`,
+	"unclosed":      "<html><head><title>Broken</title></head><body><h1>oops<a href='/x'>log in",
+}
+
+func TestParseStream_MatchesParse(t *testing.T) {
+	for name, doc := range parityFixtures {
+		t.Run(name, func(t *testing.T) {
+			u := mustURL("http://test.local/dir/page.html")
+
+			want, err := parser.Parse(strings.NewReader(doc), u)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := parser.ParseStream(strings.NewReader(doc), u)
+			if err != nil {
+				t.Fatalf("ParseStream: %v", err)
+			}
+
+			want.Doc = nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseStream mismatch\n got: %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+// largeDocument builds a synthetic page of roughly the given number of
+// sections, each with headings, links, images and a form.
+func largeDocument(sections int) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><head><title>Large</title>")
+	b.WriteString(`<link rel="stylesheet" href="/site.css"><script src="https://cdn.example.com/app.js"></script></head><body>`)
+	for i := 0; i < sections; i++ {
+		fmt.Fprintf(&b, `<section><h2>Section %d</h2><p>Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.</p>`, i)
+		fmt.Fprintf(&b, `<ul><li><a href="/item/%d">Item</a></li><li><a href="https://other.example/%d">Other</a></li></ul>`, i, i)
+		fmt.Fprintf(&b, `<img src="/img/%d.png" srcset="/img/%d@2x.png 2x" alt="">`, i, i)
+		b.WriteString(`<form><input name="q"><button>Search</button></form></section>`)
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func benchmarkParser(b *testing.B, parse func(string) error, sections int) {
+	doc := largeDocument(sections)
+	b.SetBytes(int64(len(doc)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := parse(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	u := mustURL("http://test.local/")
+	for _, n := range []int{100, 5000} {
+		b.Run(fmt.Sprintf("sections=%d", n), func(b *testing.B) {
+			benchmarkParser(b, func(doc string) error {
+				_, err := parser.Parse(strings.NewReader(doc), u)
+				return err
+			}, n)
+		})
+	}
+}
+
+func BenchmarkParseStream(b *testing.B) {
+	u := mustURL("http://test.local/")
+	for _, n := range []int{100, 5000} {
+		b.Run(fmt.Sprintf("sections=%d", n), func(b *testing.B) {
+			benchmarkParser(b, func(doc string) error {
+				_, err := parser.ParseStream(strings.NewReader(doc), u)
+				return err
+			}, n)
+		})
+	}
+}
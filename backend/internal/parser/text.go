@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// maxVisibleText caps the collected visible text. Statistics over the first
+// megabyte of prose are representative enough.
+const maxVisibleText = 1 << 20
+
+// invisibleTags never render their content as page text.
+var invisibleTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"title": true, "iframe": true, "noembed": true, "noframes": true,
+	"object": true, "svg": true,
+}
+
+// inlineTags do not break the text; any other element boundary starts a new
+// line.
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true,
+	"code": true, "data": true, "dfn": true, "em": true, "font": true, "i": true,
+	"kbd": true, "mark": true, "q": true, "s": true, "samp": true, "small": true,
+	"span": true, "strong": true, "sub": true, "sup": true, "time": true,
+	"u": true, "var": true,
+}
+
+// voidTags have no content and no end tag.
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "keygen": true, "link": true,
+	"meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// closesP are the start tags that close an open <p> element. The end tags of
+// the block elements among them close it too.
+var closesP = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"center": true, "details": true, "dialog": true, "dir": true, "div": true,
+	"dl": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hgroup": true,
+	"hr": true, "li": true, "listing": true, "main": true, "menu": true,
+	"nav": true, "ol": true, "p": true, "plaintext": true, "pre": true,
+	"search": true, "section": true, "summary": true, "table": true,
+	"ul": true, "xmp": true, "dd": true, "dt": true,
+}
+
+// impliedEnd describes an element whose end tag may be omitted. closedBy are
+// the start tags that close it. scope are the elements that shield their
+// content from closedBy, such as a list nested in an <li>; the end tag of
+// one that was open before the element closes it as well.
+type impliedEnd struct {
+	closedBy map[string]bool
+	scope    map[string]bool
+}
+
+// impliedEnds are the elements the tree builder closes without an end tag.
+var impliedEnds = map[string]impliedEnd{
+	"p": {
+		closedBy: closesP,
+		scope:    map[string]bool{"applet": true, "button": true, "caption": true, "marquee": true, "object": true, "td": true, "th": true},
+	},
+	"li": {
+		closedBy: map[string]bool{"li": true},
+		scope:    map[string]bool{"ol": true, "ul": true},
+	},
+	"dt": {
+		closedBy: map[string]bool{"dd": true, "dt": true},
+		scope:    map[string]bool{"dl": true},
+	},
+	"dd": {
+		closedBy: map[string]bool{"dd": true, "dt": true},
+		scope:    map[string]bool{"dl": true},
+	},
+	"option": {
+		closedBy: map[string]bool{"optgroup": true, "option": true},
+		scope:    map[string]bool{"datalist": true, "optgroup": true, "select": true},
+	},
+}
+
+// hiddenElement reports whether the element, and everything inside it, is
+// excluded from the visible text: invisible tags, the hidden attribute,
+// aria-hidden="true" and inline display:none or visibility:hidden.
+func hiddenElement(tag string, attrs []html.Attribute) bool {
+	if invisibleTags[tag] {
+		return true
+	}
+	if _, ok := attr(attrs, "hidden"); ok {
+		return true
+	}
+	if v, _ := attr(attrs, "aria-hidden"); strings.EqualFold(strings.TrimSpace(v), "true") {
+		return true
+	}
+	style, _ := attr(attrs, "style")
+	style = strings.ToLower(strings.Join(strings.Fields(style), ""))
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
+
+// textBuffer accumulates visible text with whitespace collapsed to single
+// spaces and block boundaries as newlines, so the tree and streaming parsers
+// produce the same string however their text nodes are split.
+type textBuffer struct {
+	b     strings.Builder
+	space bool
+	brk   bool
+}
+
+func (t *textBuffer) write(s string) {
+	for _, r := range s {
+		if t.b.Len() >= maxVisibleText {
+			return
+		}
+		if unicode.IsSpace(r) {
+			t.space = true
+			continue
+		}
+		if t.b.Len() > 0 {
+			switch {
+			case t.brk:
+				t.b.WriteByte('\n')
+			case t.space:
+				t.b.WriteByte(' ')
+			}
+		}
+		t.space, t.brk = false, false
+		t.b.WriteRune(r)
+	}
+}
+
+// boundary marks an element boundary, which breaks the text unless the
+// element is inline.
+func (t *textBuffer) boundary(tag string) {
+	if !inlineTags[tag] {
+		t.brk = true
+	}
+}
+
+func (t *textBuffer) String() string {
+	return t.b.String()
+}
+
+// visibleText returns the text a reader would see in the document.
+func visibleText(root *html.Node) string {
+	var buf textBuffer
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			buf.write(n.Data)
+			return
+		case html.ElementNode:
+			tag := strings.ToLower(n.Data)
+			if hiddenElement(tag, n.Attr) {
+				buf.boundary(tag)
+				return
+			}
+			buf.boundary(tag)
+			defer buf.boundary(tag)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return buf.String()
+}
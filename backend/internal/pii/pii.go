@@ -0,0 +1,247 @@
+// Package pii looks for personal data and secrets a page exposes: email
+// addresses, phone numbers and card numbers in the visible text and
+// attributes, and API keys and tokens in inline scripts. Matched values are
+// masked before they leave the package.
+package pii
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// Kinds of exposed values.
+const (
+	KindEmail     = "email"
+	KindPhone     = "phone"
+	KindCard      = "card_number"
+	KindAWSKey    = "aws_access_key"
+	KindAWSSecret = "aws_secret_key"
+	KindBearer    = "bearer_token"
+	KindAPIKey    = "api_key"
+)
+
+// maxHits bounds the hits reported for one page.
+const maxHits = 200
+
+var (
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.([A-Za-z]{2,24})\b`)
+	cardRe  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	phoneRe = regexp.MustCompile(`\+?\(?\b\d[\d ().-]{6,}\d\b`)
+	ipv4Re  = regexp.MustCompile(`^\d{1,3}(?:\.\d{1,3}){3}$`)
+	// dateRe matches ISO and day-first dates, optionally followed by a time,
+	// which the phone pattern would otherwise take for a number.
+	dateRe = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}|\d{1,2}[./]\d{1,2}[./]\d{4})(?:$|[ T])`)
+
+	awsKeyRe    = regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)
+	awsSecretRe = regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']([A-Za-z0-9/+=]{40})["']`)
+	bearerRe    = regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{20,}=*)`)
+	apiKeyRe    = regexp.MustCompile(`(?i)\b(?:api[_-]?key|api[_-]?secret|access[_-]?token|auth[_-]?token|client[_-]?secret|secret[_-]?key)["']?\s*[:=]\s*["']([A-Za-z0-9_\-./+=]{16,})["']`)
+)
+
+// fileTLDs are file extensions that make name@2x.png look like an address.
+var fileTLDs = map[string]bool{
+	"png": true, "jpg": true, "jpeg": true, "gif": true, "svg": true,
+	"webp": true, "avif": true, "css": true, "js": true,
+}
+
+// Hit is one exposed value.
+type Hit struct {
+	Kind string
+	// Value is the masked match.
+	Value string
+	// Location is "text line N" for the visible text, "tag[attribute] #N"
+	// for an attribute of the Nth element with that tag and "inline script
+	// N" for a script, counting from 1.
+	Location string
+}
+
+type scanner struct {
+	hits []Hit
+	seen map[string]bool
+}
+
+func (s *scanner) add(kind, raw, loc string) {
+	key := kind + "\x00" + raw + "\x00" + loc
+	if len(s.hits) == maxHits || s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+	s.hits = append(s.hits, Hit{Kind: kind, Value: mask(kind, raw), Location: loc})
+}
+
+// Scan looks for exposed values in the visible text, the text-bearing
+// attributes and the inline scripts of a page.
+func Scan(text string, attrs []parser.AttrValue, scripts []parser.InlineScript) []Hit {
+	s := &scanner{seen: map[string]bool{}}
+	for i, line := range strings.Split(text, "\n") {
+		s.contact(line, fmt.Sprintf("text line %d", i+1))
+	}
+	for _, a := range attrs {
+		s.contact(a.Value, fmt.Sprintf("%s[%s] #%d", a.Tag, a.Name, a.Index))
+	}
+	for i, sc := range scripts {
+		s.secrets(sc.Content, fmt.Sprintf("inline script %d", i+1))
+	}
+	return s.hits
+}
+
+// contact finds email addresses, card numbers and phone numbers. Digits
+// that form a valid card number are not reported again as a phone number.
+func (s *scanner) contact(v, loc string) {
+	for _, m := range emailRe.FindAllStringSubmatch(v, -1) {
+		if !fileTLDs[strings.ToLower(m[1])] {
+			s.add(KindEmail, m[0], loc)
+		}
+	}
+
+	var cards [][]int
+	for _, m := range cardRe.FindAllStringIndex(v, -1) {
+		if luhn(v[m[0]:m[1]]) {
+			cards = append(cards, m)
+			s.add(KindCard, v[m[0]:m[1]], loc)
+		}
+	}
+	for _, m := range phoneRe.FindAllStringIndex(v, -1) {
+		if overlaps(m, cards) {
+			continue
+		}
+		if p := strings.TrimSpace(v[m[0]:m[1]]); phoneLike(p) {
+			s.add(KindPhone, p, loc)
+		}
+	}
+}
+
+func (s *scanner) secrets(v, loc string) {
+	for _, re := range []struct {
+		kind string
+		re   *regexp.Regexp
+	}{
+		{KindAWSKey, awsKeyRe},
+		{KindAWSSecret, awsSecretRe},
+		{KindBearer, bearerRe},
+		{KindAPIKey, apiKeyRe},
+	} {
+		for _, m := range re.re.FindAllStringSubmatch(v, -1) {
+			s.add(re.kind, m[1], loc)
+		}
+	}
+}
+
+func overlaps(m []int, spans [][]int) bool {
+	for _, s := range spans {
+		if m[0] < s[1] && s[0] < m[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func digits(v string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, v)
+}
+
+// phoneLike reports whether a run of digits and separators reads as a phone
+// number: 9 to 15 digits, written with a leading + or with separators, and
+// neither an IPv4 address nor a date and time.
+func phoneLike(v string) bool {
+	n := len(digits(v))
+	if n < 9 || n > 15 || ipv4Re.MatchString(v) || dateRe.MatchString(v) {
+		return false
+	}
+	return strings.HasPrefix(v, "+") || strings.ContainsAny(v, " ().-")
+}
+
+// luhn reports whether the digits of v pass the Luhn checksum and are not a
+// single repeated digit.
+func luhn(v string) bool {
+	d := digits(v)
+	if strings.Count(d, d[:1]) == len(d) {
+		return false
+	}
+	sum := 0
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if (len(d)-i)%2 == 0 {
+			if n *= 2; n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// mask hides all but a hint of a value: the first letter and the domain of
+// an email address, the last digits of a number and the first characters of
+// a secret.
+func mask(kind, v string) string {
+	switch kind {
+	case KindEmail:
+		local, domain, _ := strings.Cut(v, "@")
+		return local[:1] + "***@" + domain
+	case KindPhone:
+		return maskDigits(v, 2)
+	case KindCard:
+		return maskDigits(v, 4)
+	default:
+		if len(v) <= 8 {
+			return "****"
+		}
+		return v[:4] + "****"
+	}
+}
+
+// maskDigits replaces every digit but the last keep with '*', leaving
+// separators in place.
+func maskDigits(v string, keep int) string {
+	left := len(digits(v)) - keep
+	b := []byte(v)
+	for i, c := range b {
+		if left == 0 {
+			break
+		}
+		if c >= '0' && c <= '9' {
+			b[i] = '*'
+			left--
+		}
+	}
+	return string(b)
+}
+
+// Findings summarizes hits by kind. Card numbers and secrets are high
+// severity; contact details are informational, since many pages publish
+// them on purpose.
+func Findings(hits []Hit) []contract.Finding {
+	byKind := map[string][]Hit{}
+	for _, h := range hits {
+		byKind[h.Kind] = append(byKind[h.Kind], h)
+	}
+
+	var findings []contract.Finding
+	summarize := func(code, severity string, list []Hit, what string) {
+		if len(list) == 0 {
+			return
+		}
+		findings = append(findings, contract.Finding{Code: code, Severity: severity,
+			Message: fmt.Sprintf("%d %s found, e.g. %s in %s", len(list), what, list[0].Value, list[0].Location),
+			Element: list[0].Location})
+	}
+	summarize("email_exposed", contract.SeverityInfo, byKind[KindEmail], "email address(es)")
+	summarize("phone_exposed", contract.SeverityInfo, byKind[KindPhone], "phone number(s)")
+	summarize("card_number_exposed", contract.SeverityHigh, byKind[KindCard], "card-like number(s) passing the Luhn check")
+	var secrets []Hit
+	for _, k := range []string{KindAWSKey, KindAWSSecret, KindBearer, KindAPIKey} {
+		secrets = append(secrets, byKind[k]...)
+	}
+	summarize("secret_exposed", contract.SeverityHigh, secrets, "API key or token(s) in inline scripts")
+	return findings
+}
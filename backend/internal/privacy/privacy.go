@@ -0,0 +1,242 @@
+// Package privacy audits the third parties a page pulls in, the cookies its
+// response sets and whether a consent-management platform is loaded.
+// Third parties are grouped by registrable domain (eTLD+1) and categorized
+// from an embedded tracker list.
+package privacy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Categories reported for third-party domains.
+const (
+	CategoryAnalytics   = "analytics"
+	CategoryAdvertising = "advertising"
+	CategorySocial      = "social"
+	CategoryCDN         = "cdn"
+	CategoryConsent     = "consent"
+	CategoryOther       = "other"
+)
+
+//go:embed trackers.json
+var trackersJSON []byte
+
+// tracker is an entry of the embedded list, keyed by domain. An entry
+// applies to the domain and all its subdomains.
+type tracker struct {
+	Category string `json:"category"`
+	Company  string `json:"company"`
+	CMP      string `json:"cmp"`
+}
+
+var trackers = func() map[string]tracker {
+	m := map[string]tracker{}
+	if err := json.Unmarshal(trackersJSON, &m); err != nil {
+		panic("privacy: invalid trackers.json: " + err.Error())
+	}
+	return m
+}()
+
+// cmpGlobals are JavaScript APIs defined by consent-management platforms,
+// looked for in inline scripts.
+var cmpGlobals = []struct{ marker, name string }{
+	{"__tcfapi", "IAB TCF CMP"},
+	{"__cmp(", "IAB TCF CMP"},
+	{"__gpp", "IAB GPP CMP"},
+	{"OneTrust", "OneTrust"},
+	{"Cookiebot", "Cookiebot"},
+}
+
+// ThirdParty is a registrable domain other than the page's own.
+type ThirdParty struct {
+	Domain   string
+	Category string
+	Company  string
+	Hosts    []string
+	Kinds    []string
+	Requests int
+}
+
+// Cookie is a cookie set by the page response.
+type Cookie struct {
+	Name     string
+	Domain   string
+	Path     string
+	Secure   bool
+	HTTPOnly bool
+	SameSite string
+	// Expires is zero for session cookies.
+	Expires time.Time
+}
+
+// ConsentManager reports whether a consent-management platform was found.
+type ConsentManager struct {
+	Present  bool
+	Name     string
+	Evidence string
+}
+
+// Report is the privacy view of a page.
+type Report struct {
+	ThirdParties   []ThirdParty
+	Cookies        []Cookie
+	ConsentManager ConsentManager
+}
+
+// Audit builds the report from the page URL, its parsed subresources and
+// inline scripts, and the response header. pageURL is the first party: the
+// URL the page was served from after redirects.
+func Audit(pageURL *url.URL, resources []parser.Resource, inline []parser.InlineScript, header http.Header) Report {
+	var rep Report
+	own := RegistrableDomain(pageURL.Hostname())
+
+	byDomain := map[string]*ThirdParty{}
+	for _, r := range resources {
+		u, err := url.Parse(r.URL)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		domain := RegistrableDomain(host)
+		if domain == own {
+			continue
+		}
+
+		tp := byDomain[domain]
+		if tp == nil {
+			tp = &ThirdParty{Domain: domain, Category: CategoryOther}
+			byDomain[domain] = tp
+		}
+		tp.Requests++
+		tp.Hosts = appendUnique(tp.Hosts, host)
+		tp.Kinds = appendUnique(tp.Kinds, r.Kind)
+
+		if t, ok := lookup(host); ok {
+			// Hosts of one domain can match different entries, e.g.
+			// analytics.google.com and adservice.google.com; the first
+			// categorized host wins.
+			if tp.Category == CategoryOther {
+				tp.Category, tp.Company = t.Category, t.Company
+			}
+			if t.CMP != "" && r.Kind == parser.KindScript && !rep.ConsentManager.Present {
+				rep.ConsentManager = ConsentManager{Present: true, Name: t.CMP, Evidence: "script " + r.URL}
+			}
+		}
+	}
+	for _, tp := range byDomain {
+		sort.Strings(tp.Hosts)
+		sort.Strings(tp.Kinds)
+		rep.ThirdParties = append(rep.ThirdParties, *tp)
+	}
+	sort.Slice(rep.ThirdParties, func(i, j int) bool {
+		a, b := rep.ThirdParties[i], rep.ThirdParties[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Domain < b.Domain
+	})
+
+	if !rep.ConsentManager.Present {
+		rep.ConsentManager = inlineCMP(inline)
+	}
+	rep.Cookies = responseCookies(header)
+	return rep
+}
+
+// RegistrableDomain returns the eTLD+1 of host, e.g. example.co.uk for
+// www.example.co.uk. IP addresses and hosts without a public suffix are
+// returned unchanged.
+func RegistrableDomain(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	d, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return d
+}
+
+// lookup finds the tracker entry for host or its closest parent domain.
+func lookup(host string) (tracker, bool) {
+	for h := host; h != ""; {
+		if t, ok := trackers[h]; ok {
+			return t, true
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return tracker{}, false
+}
+
+func inlineCMP(scripts []parser.InlineScript) ConsentManager {
+	for _, s := range scripts {
+		for _, g := range cmpGlobals {
+			if strings.Contains(s.Content, g.marker) {
+				return ConsentManager{Present: true, Name: g.name, Evidence: "inline script uses " + strings.TrimSuffix(g.marker, "(")}
+			}
+		}
+	}
+	return ConsentManager{}
+}
+
+func responseCookies(header http.Header) []Cookie {
+	var out []Cookie
+	for _, line := range header.Values("Set-Cookie") {
+		c, err := http.ParseSetCookie(line)
+		if err != nil {
+			continue
+		}
+		ck := Cookie{
+			Name:     c.Name,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+			SameSite: sameSite(c.SameSite),
+		}
+		switch {
+		case c.MaxAge > 0:
+			ck.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second).UTC().Truncate(time.Second)
+		case c.MaxAge == 0 && !c.Expires.IsZero():
+			ck.Expires = c.Expires.UTC()
+		}
+		out = append(out, ck)
+	}
+	return out
+}
+
+func sameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,109 @@
+package privacy_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/privacy"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	cases := map[string]string{
+		"www.example.co.uk":       "example.co.uk",
+		"a.b.example.com":         "example.com",
+		"example.com.":            "example.com",
+		"user.github.io":          "user.github.io",
+		"127.0.0.1":               "127.0.0.1",
+		"localhost":               "localhost",
+		"Stats.G.DoubleClick.NET": "doubleclick.net",
+	}
+	for host, want := range cases {
+		if got := privacy.RegistrableDomain(host); got != want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	page, _ := url.Parse("https://www.example.co.uk/article")
+	resources := []parser.Resource{
+		{Kind: parser.KindImage, URL: "https://static.example.co.uk/logo.png"},
+		{Kind: parser.KindScript, URL: "https://www.google-analytics.com/analytics.js"},
+		{Kind: parser.KindScript, URL: "https://www.googletagmanager.com/gtag/js?id=G-1"},
+		{Kind: parser.KindImage, URL: "https://stats.g.doubleclick.net/pixel.gif"},
+		{Kind: parser.KindStylesheet, URL: "https://fonts.googleapis.com/css2?family=Inter"},
+		{Kind: parser.KindCSS, URL: "https://fonts.gstatic.com/s/inter.woff2"},
+		{Kind: parser.KindScript, URL: "https://cdn.cookielaw.org/scripttemplates/otSDKStub.js"},
+		{Kind: parser.KindIframe, URL: "https://widgets.unknown.io/embed"},
+		{Kind: parser.KindImage, URL: "https://widgets.unknown.io/a.png"},
+	}
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=abc; Path=/; Secure; HttpOnly; SameSite=Lax")
+	header.Add("Set-Cookie", "prefs=1; Domain=example.co.uk; Max-Age=3600")
+
+	rep := privacy.Audit(page, resources, nil, header)
+
+	got := map[string]privacy.ThirdParty{}
+	for _, tp := range rep.ThirdParties {
+		got[tp.Domain] = tp
+	}
+	if _, ok := got["example.co.uk"]; ok {
+		t.Error("first-party subdomains must not be reported as third parties")
+	}
+	want := map[string]string{
+		"google-analytics.com": privacy.CategoryAnalytics,
+		"googletagmanager.com": privacy.CategoryAnalytics,
+		"doubleclick.net":      privacy.CategoryAdvertising,
+		// googleapis.com is itself a public suffix.
+		"fonts.googleapis.com": privacy.CategoryCDN,
+		"gstatic.com":          privacy.CategoryCDN,
+		"cookielaw.org":        privacy.CategoryConsent,
+		"unknown.io":           privacy.CategoryOther,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d third parties, got %+v", len(want), rep.ThirdParties)
+	}
+	for domain, cat := range want {
+		if got[domain].Category != cat {
+			t.Errorf("expected %s to be %s, got %+v", domain, cat, got[domain])
+		}
+	}
+	if u := got["unknown.io"]; u.Requests != 2 || len(u.Kinds) != 2 || u.Hosts[0] != "widgets.unknown.io" {
+		t.Errorf("unexpected grouping for unknown.io: %+v", u)
+	}
+	if rep.ThirdParties[0].Domain != "unknown.io" {
+		t.Errorf("expected domains ordered by requests, got %s first", rep.ThirdParties[0].Domain)
+	}
+
+	if cm := rep.ConsentManager; !cm.Present || cm.Name != "OneTrust" {
+		t.Errorf("expected OneTrust consent manager, got %+v", cm)
+	}
+
+	if len(rep.Cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %+v", rep.Cookies)
+	}
+	if c := rep.Cookies[0]; c.Name != "session" || !c.Secure || !c.HTTPOnly || c.SameSite != "Lax" || !c.Expires.IsZero() {
+		t.Errorf("unexpected session cookie %+v", c)
+	}
+	if c := rep.Cookies[1]; c.Domain != "example.co.uk" || c.Expires.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("unexpected persistent cookie %+v", c)
+	}
+}
+
+func TestAudit_InlineConsentManager(t *testing.T) {
+	page, _ := url.Parse("https://example.com/")
+	rep := privacy.Audit(page, nil, []parser.InlineScript{
+		{Content: "window.dataLayer = [];"},
+		{Content: "if (typeof __tcfapi === 'function') { __tcfapi('addEventListener', 2, cb) }"},
+	}, http.Header{})
+
+	if cm := rep.ConsentManager; !cm.Present || cm.Name != "IAB TCF CMP" {
+		t.Errorf("expected a TCF consent manager from the inline script, got %+v", cm)
+	}
+	if len(rep.ThirdParties) != 0 || len(rep.Cookies) != 0 {
+		t.Errorf("expected an otherwise empty report, got %+v", rep)
+	}
+}
@@ -0,0 +1,76 @@
+package rendering
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+// Redirect kinds.
+const (
+	KindMetaRefresh = "meta-refresh"
+	KindJavaScript  = "javascript"
+)
+
+// Redirect is a client-side redirect declared by a page.
+type Redirect struct {
+	Kind string
+	// Target is the destination as written; URL is Target resolved against
+	// the document base, or empty when it cannot be resolved.
+	Target string
+	URL    string
+	// Delay is the meta refresh delay in seconds.
+	Delay float64
+}
+
+// jsRedirectRe matches assignments to the location and calls to
+// location.replace/assign with a string literal, e.g.
+// window.location.href = "/login". location must be global or a property of
+// window, document, self or top: user.location = "Berlin" is not a redirect.
+var jsRedirectRe = regexp.MustCompile(`(?:\b(?:window|document|self|top)\.|^|[^\w$.])location` +
+	`(?:(?:\.href)?\s*=\s*(["'])([^"'\n]+)["']|\.(?:replace|assign)\(\s*(["'])([^"'\n]+)["']\s*\))`)
+
+// Redirects lists the meta refresh and the JavaScript redirects in inline
+// scripts of p. Relative targets are resolved against base. A meta refresh
+// without a url reloads the page and is not a redirect.
+func Redirects(p *parser.Parsed, base *url.URL) []Redirect {
+	var out []Redirect
+	if r := p.Refresh; r != nil && r.Target != "" {
+		out = append(out, Redirect{Kind: KindMetaRefresh, Target: r.Target, URL: r.URL, Delay: r.Delay})
+	}
+
+	seen := map[string]bool{}
+	for _, s := range p.InlineScripts {
+		if !isJavaScript(s.Type) {
+			continue
+		}
+		for _, m := range jsRedirectRe.FindAllStringSubmatch(s.Content, -1) {
+			target := m[2]
+			if target == "" {
+				target = m[4]
+			}
+			target = strings.TrimSpace(target)
+			// Fragment changes and javascript: URLs do not leave the page.
+			if seen[target] || strings.HasPrefix(target, "#") || strings.HasPrefix(strings.ToLower(target), "javascript:") {
+				continue
+			}
+			seen[target] = true
+			rd := Redirect{Kind: KindJavaScript, Target: target}
+			if u, err := base.Parse(target); err == nil && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https") {
+				rd.URL = u.String()
+			}
+			out = append(out, rd)
+		}
+	}
+	return out
+}
+
+func isJavaScript(typ string) bool {
+	switch typ {
+	case "", "module", "text/javascript", "application/javascript", "text/ecmascript", "application/ecmascript":
+		return true
+	}
+	return false
+}
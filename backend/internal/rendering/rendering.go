@@ -0,0 +1,154 @@
+// Package rendering tells whether a page is a client-side-rendered shell,
+// whose content only appears once JavaScript runs, and finds the meta
+// refresh and JavaScript redirects it declares.
+package rendering
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+// maxShellWords is the most visible words a page can have and still count
+// as a shell.
+const maxShellWords = 50
+
+// largeInlineScript is the inline script size, in bytes, that counts as an
+// application bundle.
+const largeInlineScript = 100 << 10
+
+// rootNodes are the mount points of common frameworks.
+var rootNodes = []struct{ selector, framework string }{
+	{"#__next", "Next.js"},
+	{"#__nuxt", "Nuxt"},
+	{"#___gatsby", "Gatsby"},
+	{"app-root, [ng-version]", "Angular"},
+	{"[ng-app], [data-ng-app]", "AngularJS"},
+	{"[data-reactroot]", "React"},
+	{"#root", "React"},
+	{"#app", "Vue"},
+	{"#svelte", "Svelte"},
+	{"#ember-app, .ember-application", "Ember"},
+}
+
+var (
+	bundleRe = regexp.MustCompile(`(?i)^(main|app|bundle|index|vendors?|runtime|chunk|polyfills|framework)([.-][0-9a-z_-]*)?[.-][0-9a-f]{6,}(\.chunk)?\.m?js$|\.chunk\.m?js$|^(app|bundle|main)\.m?js$`)
+	// bundleDirs identify a framework from where its bundles are served.
+	bundleDirs = []struct{ dir, framework string }{
+		{"/_next/static/", "Next.js"},
+		{"/_nuxt/", "Nuxt"},
+		{"/static/js/", "React"},
+	}
+	noscriptRe = regexp.MustCompile(`(?i)(enable|turn on|requires?|need).{0,30}javascript|javascript.{0,30}(enabled|required|disabled)`)
+)
+
+// Shell reports whether a page looks client-side rendered and why.
+type Shell struct {
+	Detected  bool
+	Framework string
+	Signals   []string
+}
+
+// DetectShell looks for a client-side-rendered shell: little visible text
+// together with a framework root node, application bundles or a noscript
+// notice. The root node and noscript checks need p.Doc.
+func DetectShell(p *parser.Parsed) Shell {
+	var sh Shell
+	words := len(strings.Fields(p.Text))
+	tiny := words < maxShellWords
+	if tiny {
+		sh.Signals = append(sh.Signals, fmt.Sprintf("only %d words of visible text", words))
+	}
+
+	strong := false
+	if p.Doc != nil {
+		if where, fw := emptyRoot(p.Doc); fw != "" {
+			sh.Framework = fw
+			sh.Signals = append(sh.Signals, fmt.Sprintf("empty %s root node at %s", fw, where))
+			strong = true
+		}
+		if notice := noscriptNotice(p.Doc); notice != "" {
+			sh.Signals = append(sh.Signals, fmt.Sprintf("noscript notice %q", notice))
+			strong = true
+		}
+	}
+
+	var bundles []string
+	for _, r := range p.Resources {
+		if r.Kind != parser.KindScript {
+			continue
+		}
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			continue
+		}
+		fw := ""
+		for _, d := range bundleDirs {
+			if strings.Contains(u.Path, d.dir) {
+				fw = d.framework
+			}
+		}
+		if fw != "" || bundleRe.MatchString(path.Base(u.Path)) {
+			bundles = append(bundles, path.Base(u.Path))
+			if sh.Framework == "" {
+				sh.Framework = fw
+			}
+		}
+	}
+	if len(bundles) > 0 {
+		sh.Signals = append(sh.Signals, fmt.Sprintf("%d application bundle(s): %s", len(bundles), strings.Join(bundles, ", ")))
+		strong = true
+	}
+	for _, s := range p.InlineScripts {
+		if len(s.Content) >= largeInlineScript {
+			sh.Signals = append(sh.Signals, fmt.Sprintf("%d KiB inline script", len(s.Content)>>10))
+			strong = true
+			break
+		}
+	}
+
+	sh.Detected = tiny && strong
+	return sh
+}
+
+// emptyRoot returns the path and framework of the first framework root node
+// without visible text.
+func emptyRoot(doc *goquery.Document) (string, string) {
+	for _, r := range rootNodes {
+		sel := doc.Find(r.selector).First()
+		if sel.Length() == 0 {
+			continue
+		}
+		if len(strings.Fields(sel.Text())) < maxShellWords/5 {
+			return parser.ElementPath(sel.Nodes[0]), r.framework
+		}
+	}
+	return "", ""
+}
+
+func noscriptNotice(doc *goquery.Document) string {
+	var notice string
+	doc.Find("noscript").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		// noscript content is raw text when scripting is on, as it is for
+		// the tree builder; parse it to drop any markup.
+		text := s.Text()
+		if inner, err := goquery.NewDocumentFromReader(strings.NewReader(text)); err == nil {
+			text = inner.Text()
+		}
+		text = strings.Join(strings.Fields(text), " ")
+		if noscriptRe.MatchString(text) {
+			if r := []rune(text); len(r) > 120 {
+				text = string(r[:120]) + "…"
+			}
+			notice = text
+			return false
+		}
+		return true
+	})
+	return notice
+}
@@ -0,0 +1,91 @@
+package rendering_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/rendering"
+)
+
+func parse(t *testing.T, page string, stream bool) *parser.Parsed {
+	t.Helper()
+	u, _ := url.Parse("https://example.com/app/")
+	parse := parser.Parse
+	if stream {
+		parse = parser.ParseStream
+	}
+	p, err := parse(strings.NewReader(page), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+const craShell = `<!doctype html><html><head><title>App</title>
+<script defer src="/static/js/main.3f2a9c1d.js"></script></head>
+<body><noscript>You need to enable JavaScript to run this app.</noscript><div id="root"></div></body></html>`
+
+func TestDetectShell(t *testing.T) {
+	sh := rendering.DetectShell(parse(t, craShell, false))
+	if !sh.Detected || sh.Framework != "React" {
+		t.Fatalf("expected a React shell, got %+v", sh)
+	}
+	want := []string{
+		"only 0 words of visible text",
+		"empty React root node at html > body > div#root",
+		`noscript notice "You need to enable JavaScript to run this app."`,
+		"1 application bundle(s): main.3f2a9c1d.js",
+	}
+	if strings.Join(sh.Signals, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected signals:\n got %q\nwant %q", sh.Signals, want)
+	}
+
+	// Without the tree only the text and bundles are available.
+	if sh := rendering.DetectShell(parse(t, craShell, true)); !sh.Detected || len(sh.Signals) != 2 {
+		t.Errorf("expected a shell from text and bundles alone, got %+v", sh)
+	}
+}
+
+func TestDetectShell_ServerRendered(t *testing.T) {
+	body := strings.Repeat("<p>Server rendered paragraphs carry plenty of visible words for readers.</p>", 10)
+	page := `<html><body><div id="__next">` + body + `</div><script src="/_next/static/chunks/main-1a2b3c4d5e.js"></script></body></html>`
+
+	sh := rendering.DetectShell(parse(t, page, false))
+	if sh.Detected {
+		t.Errorf("a server-rendered page is not a shell: %+v", sh)
+	}
+	if sh.Framework != "Next.js" {
+		t.Errorf("expected the framework from the bundle path, got %q", sh.Framework)
+	}
+
+	if sh := rendering.DetectShell(parse(t, `<html><body><p>Short page.</p></body></html>`, false)); sh.Detected {
+		t.Errorf("little text alone is not a shell: %+v", sh)
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	page := `<html><head><meta http-equiv="refresh" content="0; url=/moved">
+<script>if (!ok) { window.location.href = "/login"; }</script>
+<script>location.replace('https://other.example/x'); top.location = "/login";</script>
+<script type="application/ld+json">{"location": "x"}</script>
+<script>document.location='javascript:void(0)'; location.href = "#top"</script>
+<script>user.location = "Berlin"; cfg.location = "/x"; $location = "/y"; geolocation = "/z";</script>
+</head></html>`
+
+	got := rendering.Redirects(parse(t, page, false), &url.URL{Scheme: "https", Host: "example.com", Path: "/app/"})
+	want := []rendering.Redirect{
+		{Kind: rendering.KindMetaRefresh, Target: "/moved", URL: "https://example.com/moved"},
+		{Kind: rendering.KindJavaScript, Target: "/login", URL: "https://example.com/login"},
+		{Kind: rendering.KindJavaScript, Target: "https://other.example/x", URL: "https://other.example/x"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d redirects, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("redirect %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
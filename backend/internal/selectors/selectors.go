@@ -0,0 +1,67 @@
+// Package selectors extracts caller-defined fields from a parsed page: each
+// field is a CSS selector whose matches yield their text or an attribute.
+package selectors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// MaxValues bounds how many values one field returns.
+const MaxValues = 100
+
+// Rule describes one field.
+type Rule struct {
+	Selector string
+	// Attr is the attribute to read; when empty the element text is used,
+	// with whitespace collapsed.
+	Attr string
+	// Multiple returns every match instead of the first.
+	Multiple bool
+}
+
+// Field is the outcome of one rule. Matches counts the matched elements;
+// in attribute mode Values only holds those that carry the attribute. Err
+// is set when the selector is invalid.
+type Field struct {
+	Matches int
+	Values  []string
+	Err     string
+}
+
+// Extract runs every rule against doc. A rule that fails does not affect
+// the others.
+func Extract(doc *goquery.Document, rules map[string]Rule) map[string]Field {
+	out := make(map[string]Field, len(rules))
+	for name, r := range rules {
+		out[name] = extract(doc, r)
+	}
+	return out
+}
+
+func extract(doc *goquery.Document, r Rule) Field {
+	if strings.TrimSpace(r.Selector) == "" {
+		return Field{Err: "selector is empty"}
+	}
+	sel, err := cascadia.Compile(r.Selector)
+	if err != nil {
+		return Field{Err: fmt.Sprintf("invalid selector %q: %v", r.Selector, err)}
+	}
+
+	var f Field
+	doc.FindMatcher(sel).Each(func(_ int, s *goquery.Selection) {
+		f.Matches++
+		if len(f.Values) == MaxValues || !r.Multiple && len(f.Values) == 1 {
+			return
+		}
+		if r.Attr == "" {
+			f.Values = append(f.Values, strings.Join(strings.Fields(s.Text()), " "))
+		} else if v, ok := s.Attr(r.Attr); ok {
+			f.Values = append(f.Values, strings.TrimSpace(v))
+		}
+	})
+	return f
+}
@@ -0,0 +1,56 @@
+package selectors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chanaka-withanage/page-analyzer/internal/selectors"
+)
+
+const page = `<html><body>
+	<div class="product" data-sku="A-1">
+	  <span class="price">  19.99
+	    EUR </span>
+	</div>
+	<a class="tag" href="/t/shoes">Shoes</a>
+	<a class="tag">Sale</a>
+	<a class="tag" href="/t/red">Red</a>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := selectors.Extract(doc, map[string]selectors.Rule{
+		"price":   {Selector: ".price"},
+		"sku":     {Selector: ".product", Attr: "data-sku"},
+		"tags":    {Selector: "a.tag", Multiple: true},
+		"links":   {Selector: "a.tag", Attr: "href", Multiple: true},
+		"first":   {Selector: "a.tag"},
+		"missing": {Selector: ".banner"},
+		"broken":  {Selector: "div[", Multiple: true},
+		"empty":   {Selector: " "},
+	})
+
+	check := func(name string, matches int, values ...string) {
+		t.Helper()
+		f := got[name]
+		if f.Err != "" || f.Matches != matches || strings.Join(f.Values, "|") != strings.Join(values, "|") {
+			t.Errorf("%s: got %+v, want %d matches and %q", name, f, matches, values)
+		}
+	}
+	check("price", 1, "19.99 EUR")
+	check("sku", 1, "A-1")
+	check("tags", 3, "Shoes", "Sale", "Red")
+	check("links", 3, "/t/shoes", "/t/red")
+	check("first", 3, "Shoes")
+	check("missing", 0)
+
+	for _, name := range []string{"broken", "empty"} {
+		if f := got[name]; f.Err == "" || f.Matches != 0 {
+			t.Errorf("%s: expected an error, got %+v", name, f)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package similarity fingerprints the visible text of a page so that
+// mirrored, duplicated or changed pages can be recognised: an exact hash of
+// the normalized text, and a SimHash whose Hamming distance tracks how much
+// the text differs.
+package similarity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// shingleSize is the number of consecutive words hashed as one SimHash
+// feature, so that word order counts.
+const shingleSize = 3
+
+// Digest fingerprints a text.
+type Digest struct {
+	// SHA256 is the hex SHA-256 of the normalized text: lower-cased words
+	// separated by single spaces, without punctuation.
+	SHA256 string
+	// SimHash is a 64-bit SimHash of the word shingles.
+	SimHash uint64
+	Words   int
+}
+
+// Compute returns the digest of text.
+func Compute(text string) Digest {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sum := sha256.Sum256([]byte(strings.Join(words, " ")))
+	return Digest{
+		SHA256:  hex.EncodeToString(sum[:]),
+		SimHash: simHash(words),
+		Words:   len(words),
+	}
+}
+
+func simHash(words []string) uint64 {
+	n := shingleSize
+	if len(words) < n {
+		n = len(words)
+	}
+	if n == 0 {
+		return 0
+	}
+
+	var v [64]int
+	h := fnv.New64a()
+	for i := 0; i+n <= len(words); i++ {
+		h.Reset()
+		h.Write([]byte(strings.Join(words[i:i+n], " ")))
+		f := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if f&(1<<b) != 0 {
+				v[b]++
+			} else {
+				v[b]--
+			}
+		}
+	}
+
+	var out uint64
+	for b := 0; b < 64; b++ {
+		if v[b] > 0 {
+			out |= 1 << b
+		}
+	}
+	return out
+}
+
+// FormatSimHash renders a SimHash as 16 hex digits.
+func FormatSimHash(h uint64) string {
+	return fmt.Sprintf("%016x", h)
+}
+
+// ParseSimHash reads a SimHash written by FormatSimHash.
+func ParseSimHash(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimSpace(s), 16, 64)
+}
+
+// Distance is the number of bits that differ between two SimHashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// randomDistance is the expected distance between the SimHashes of
+// unrelated texts: each bit differs with probability one half.
+const randomDistance = 32
+
+// Percent turns a SimHash distance into a similarity from 0 to 100, rounded
+// to one decimal. A distance of randomDistance or more, what unrelated texts
+// get, is 0.
+func Percent(distance int) float64 {
+	d := min(max(distance, 0), randomDistance)
+	return math.Round(float64(randomDistance-d)/randomDistance*1000) / 10
+}
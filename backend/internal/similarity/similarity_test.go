@@ -0,0 +1,57 @@
+package similarity_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/similarity"
+)
+
+func TestCompute_NormalizesText(t *testing.T) {
+	a := similarity.Compute("Hello, World!  Welcome to the site.")
+	b := similarity.Compute("hello world\nwelcome TO the site")
+	if a.SHA256 != b.SHA256 || a.SimHash != b.SimHash {
+		t.Errorf("expected equal digests, got %+v and %+v", a, b)
+	}
+	if a.Words != 6 {
+		t.Errorf("expected 6 words, got %d", a.Words)
+	}
+	if empty := similarity.Compute("  ...  "); empty.Words != 0 || empty.SimHash != 0 {
+		t.Errorf("unexpected digest for empty text %+v", empty)
+	}
+}
+
+func TestDistance_TracksEdits(t *testing.T) {
+	base := strings.Repeat("pages that share most of their text should have close fingerprints ", 10)
+	orig := similarity.Compute(base)
+	edited := similarity.Compute(base + "with one extra sentence at the end")
+	other := similarity.Compute(strings.Repeat("an entirely unrelated article about cooking rice and beans ", 10))
+
+	near := similarity.Distance(orig.SimHash, edited.SimHash)
+	far := similarity.Distance(orig.SimHash, other.SimHash)
+	if near >= far {
+		t.Errorf("expected the edit to be closer than unrelated text, got %d and %d", near, far)
+	}
+	if orig.SHA256 == edited.SHA256 {
+		t.Error("expected the exact hash to change")
+	}
+}
+
+func TestSimHash_RoundTripAndPercent(t *testing.T) {
+	h := uint64(0x0123456789abcdef)
+	s := similarity.FormatSimHash(h)
+	if s != "0123456789abcdef" {
+		t.Errorf("unexpected format %q", s)
+	}
+	if got, err := similarity.ParseSimHash(s); err != nil || got != h {
+		t.Errorf("round trip failed: %x, %v", got, err)
+	}
+	if _, err := similarity.ParseSimHash("xyz"); err == nil {
+		t.Error("expected an error for an invalid simhash")
+	}
+	for d, want := range map[int]float64{0: 100, 3: 90.6, 16: 50, 32: 0, 40: 0, 64: 0} {
+		if got := similarity.Percent(d); got != want {
+			t.Errorf("Percent(%d) = %v, want %v", d, got, want)
+		}
+	}
+}
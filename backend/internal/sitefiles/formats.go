@@ -0,0 +1,310 @@
+package sitefiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSitemapURLs is the limit the sitemaps protocol sets per file.
+const maxSitemapURLs = 50000
+
+// xmlDecoder returns a decoder that also reads Latin-1 documents, which
+// older feeds still declare.
+func xmlDecoder(data []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = func(label string, in io.Reader) (io.Reader, error) {
+		switch strings.ToLower(label) {
+		case "utf-8", "us-ascii":
+			return in, nil
+		case "iso-8859-1", "latin1", "windows-1252":
+			b, err := io.ReadAll(in)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]byte, 0, len(b))
+			for _, c := range b {
+				out = utf8.AppendRune(out, rune(c))
+			}
+			return bytes.NewReader(out), nil
+		}
+		return nil, errors.New("unsupported charset " + label)
+	}
+	return d
+}
+
+// rootElement returns the document element.
+func rootElement(d *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se, nil
+		}
+	}
+}
+
+func checkSitemap(file *File, data []byte, limit int64) {
+	data, err := gunzip(data, limit)
+	if errors.Is(err, errTooLarge) {
+		file.problem("decompresses to more than %d bytes", limit)
+		return
+	}
+	if err != nil {
+		file.problem("is not valid gzip: %v", err)
+		return
+	}
+	d := xmlDecoder(data)
+	root, err := rootElement(d)
+	if err != nil {
+		file.problem("is not well-formed XML: %v", err)
+		return
+	}
+
+	var entry, field string
+	switch root.Name.Local {
+	case "urlset":
+		entry, field = "url", "urls"
+	case "sitemapindex":
+		entry, field = "sitemap", "sitemaps"
+	default:
+		file.problem("has root element <%s>, not <urlset> or <sitemapindex>", root.Name.Local)
+		return
+	}
+	file.Fields["type"] = root.Name.Local
+
+	entries, missingLoc := 0, 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.problem("is not well-formed XML: %v", err)
+			break
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != entry {
+			continue
+		}
+		var e struct {
+			Loc string `xml:"loc"`
+		}
+		if err := d.DecodeElement(&e, &se); err != nil {
+			file.problem("is not well-formed XML: %v", err)
+			break
+		}
+		entries++
+		if strings.TrimSpace(e.Loc) == "" {
+			missingLoc++
+		}
+	}
+	file.Fields[field] = strconv.Itoa(entries)
+	switch {
+	case entries == 0:
+		file.problem("has no <%s> entries", entry)
+	case entries > maxSitemapURLs:
+		file.problem("has %d entries, more than the %d the protocol allows", entries, maxSitemapURLs)
+	}
+	if missingLoc > 0 {
+		file.problem("has %d <%s> entries without a <loc>", missingLoc, entry)
+	}
+}
+
+func checkFeed(file *File, data []byte) {
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] == '{' {
+		checkJSONFeed(file, data)
+		return
+	}
+
+	d := xmlDecoder(data)
+	root, err := rootElement(d)
+	if err != nil {
+		file.problem("is not well-formed XML: %v", err)
+		return
+	}
+	var title string
+	var items int
+	switch root.Name.Local {
+	case "rss", "RDF":
+		var doc struct {
+			Channel struct {
+				Title string     `xml:"title"`
+				Items []struct{} `xml:"item"`
+			} `xml:"channel"`
+			// RSS 1.0 items are siblings of the channel.
+			Items []struct{} `xml:"item"`
+		}
+		if err := d.DecodeElement(&doc, &root); err != nil {
+			file.problem("is not well-formed XML: %v", err)
+			return
+		}
+		file.Fields["format"] = "rss"
+		title, items = doc.Channel.Title, len(doc.Channel.Items)+len(doc.Items)
+	case "feed":
+		var doc struct {
+			Title   string     `xml:"title"`
+			Entries []struct{} `xml:"entry"`
+		}
+		if err := d.DecodeElement(&doc, &root); err != nil {
+			file.problem("is not well-formed XML: %v", err)
+			return
+		}
+		file.Fields["format"] = "atom"
+		title, items = doc.Title, len(doc.Entries)
+	default:
+		file.problem("has root element <%s>, not <rss> or <feed>", root.Name.Local)
+		return
+	}
+	setFeedFields(file, title, items)
+}
+
+func checkJSONFeed(file *File, data []byte) {
+	var doc struct {
+		Version string            `json:"version"`
+		Title   string            `json:"title"`
+		Items   []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		file.problem("is not valid JSON: %v", err)
+		return
+	}
+	file.Fields["format"] = "json"
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		file.problem("has no JSON Feed version URL")
+	}
+	setFeedFields(file, doc.Title, len(doc.Items))
+}
+
+func setFeedFields(file *File, title string, items int) {
+	title = strings.TrimSpace(title)
+	file.Fields["title"] = title
+	file.Fields["items"] = strconv.Itoa(items)
+	if title == "" {
+		file.problem("has no title")
+	}
+}
+
+// securityFields are the security.txt fields reported, by their lower-cased
+// name.
+var securityFields = map[string]string{
+	"contact":             "contact",
+	"expires":             "expires",
+	"encryption":          "encryption",
+	"policy":              "policy",
+	"acknowledgments":     "acknowledgments",
+	"preferred-languages": "preferred_languages",
+	"canonical":           "canonical",
+	"hiring":              "hiring",
+}
+
+// checkSecurityTxt validates the fields RFC 9116 requires: at least one
+// Contact and exactly one Expires date that has not passed.
+func checkSecurityTxt(file *File, data []byte, now time.Time) {
+	values := map[string][]string{}
+	lines(data, func(_ int, line string) {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return
+		}
+		if name, known := securityFields[strings.ToLower(strings.TrimSpace(k))]; known {
+			values[name] = append(values[name], strings.TrimSpace(v))
+		}
+	})
+	for name, vs := range values {
+		file.Fields[name] = strings.Join(vs, ", ")
+	}
+
+	if len(values["contact"]) == 0 {
+		file.problem("has no Contact field")
+	}
+	switch exp := values["expires"]; {
+	case len(exp) == 0:
+		file.problem("has no Expires field")
+	case len(exp) > 1:
+		file.problem("has %d Expires fields; only one is allowed", len(exp))
+	default:
+		t, err := time.Parse(time.RFC3339, exp[0])
+		switch {
+		case err != nil:
+			file.problem("has an Expires date that is not RFC 3339: %q", exp[0])
+		case !t.After(now):
+			file.problem("expired on %s", t.Format(time.DateOnly))
+		}
+	}
+}
+
+func checkManifest(file *File, data []byte) {
+	var doc struct {
+		Name      string            `json:"name"`
+		ShortName string            `json:"short_name"`
+		StartURL  string            `json:"start_url"`
+		Display   string            `json:"display"`
+		Icons     []json.RawMessage `json:"icons"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		file.problem("is not a valid JSON object: %v", err)
+		return
+	}
+	file.Fields["name"] = doc.Name
+	file.Fields["short_name"] = doc.ShortName
+	file.Fields["start_url"] = doc.StartURL
+	file.Fields["display"] = doc.Display
+	file.Fields["icons"] = strconv.Itoa(len(doc.Icons))
+	if doc.Name == "" && doc.ShortName == "" {
+		file.problem("has neither name nor short_name")
+	}
+	if len(doc.Icons) == 0 {
+		file.problem("has no icons")
+	}
+}
+
+// adsVariables are the ads.txt variable names.
+var adsVariables = map[string]bool{
+	"contact": true, "subdomain": true, "inventorypartnerdomain": true,
+	"ownerdomain": true, "managerdomain": true,
+}
+
+// checkAdsTxt counts the seller records and reports malformed lines. A
+// record is "domain, publisher ID, DIRECT or RESELLER" with an optional
+// certification authority ID.
+func checkAdsTxt(file *File, data []byte) {
+	var direct, reseller, malformed int
+	first := 0
+	lines(data, func(n int, line string) {
+		if k, _, ok := strings.Cut(line, "="); ok && adsVariables[strings.ToLower(strings.TrimSpace(k))] {
+			return
+		}
+		f := strings.Split(line, ",")
+		if (len(f) == 3 || len(f) == 4) && strings.TrimSpace(f[0]) != "" && strings.TrimSpace(f[1]) != "" {
+			switch strings.ToUpper(strings.TrimSpace(f[2])) {
+			case "DIRECT":
+				direct++
+				return
+			case "RESELLER":
+				reseller++
+				return
+			}
+		}
+		if malformed++; first == 0 {
+			first = n
+		}
+	})
+	file.Fields["records"] = strconv.Itoa(direct + reseller)
+	file.Fields["direct"] = strconv.Itoa(direct)
+	file.Fields["reseller"] = strconv.Itoa(reseller)
+	if malformed > 0 {
+		file.problem("has %d malformed line(s), the first on line %d", malformed, first)
+	}
+	if direct+reseller == 0 {
+		file.problem("has no seller records")
+	}
+}
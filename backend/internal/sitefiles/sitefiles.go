@@ -0,0 +1,311 @@
+// Package sitefiles discovers the site-level files a page links to or
+// implies: RSS, Atom and JSON feeds, sitemaps, robots.txt, security.txt,
+// the web app manifest and ads.txt. Each file is fetched and checked for
+// structure, and its key fields are reported.
+package sitefiles
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/pkg/contract"
+)
+
+// Kinds of site files.
+const (
+	KindRobots      = "robots.txt"
+	KindSitemap     = "sitemap"
+	KindFeed        = "feed"
+	KindSecurityTxt = "security.txt"
+	KindManifest    = "manifest"
+	KindAdsTxt      = "ads.txt"
+)
+
+// Sources say how a file was found.
+const (
+	// SourceLinked files are linked from the page.
+	SourceLinked = "linked"
+	// SourceRobots files are declared in robots.txt.
+	SourceRobots = "robots.txt"
+	// SourceDefault files are looked up at their conventional location.
+	SourceDefault = "default"
+)
+
+const (
+	// maxFeeds and maxSitemaps bound how many of each are fetched.
+	maxFeeds    = 5
+	maxSitemaps = 5
+	// maxProblems bounds the problems listed for one file.
+	maxProblems = 10
+)
+
+// Fetcher fetches a URL. fetch.Client satisfies it, so site files go
+// through the same SSRF guard as the page itself. MaxBytes caps response
+// bodies; gzipped sitemaps are decompressed up to the same size.
+type Fetcher interface {
+	Get(ctx context.Context, raw string) (*http.Response, io.ReadCloser, error)
+	MaxBytes() int64
+}
+
+// File is one site file and what was found at its URL. Present means a 2xx
+// response; a 404, or an HTML page at a conventional location, is reported
+// as absent without an error.
+type File struct {
+	Kind     string
+	URL      string
+	Source   string
+	Present  bool
+	Valid    bool
+	Status   int
+	Err      string
+	Problems []string
+	Fields   map[string]string
+}
+
+func (f *File) problem(format string, args ...any) {
+	if len(f.Problems) < maxProblems {
+		f.Problems = append(f.Problems, fmt.Sprintf(format, args...))
+	}
+}
+
+// Discover fetches the site files for the page at pageURL, at most
+// concurrency at a time. feeds and manifest are the links found in the
+// page; without a linked manifest, /manifest.json is tried. Sitemaps come
+// from robots.txt, or /sitemap.xml when it declares none.
+func Discover(ctx context.Context, f Fetcher, pageURL *url.URL, feeds []parser.Feed, manifest string, concurrency int, now time.Time) []File {
+	origin := &url.URL{Scheme: pageURL.Scheme, Host: pageURL.Host}
+	at := func(path string) string {
+		return origin.ResolveReference(&url.URL{Path: path}).String()
+	}
+
+	robots := File{Kind: KindRobots, URL: at("/robots.txt"), Source: SourceDefault}
+	robotsTxt := fetchFile(ctx, f, &robots, now)
+
+	var files []File
+	for _, s := range robotsSitemaps(robotsTxt) {
+		if len(files) == maxSitemaps {
+			break
+		}
+		files = append(files, File{Kind: KindSitemap, URL: s, Source: SourceRobots})
+	}
+	if len(files) == 0 {
+		files = append(files, File{Kind: KindSitemap, URL: at("/sitemap.xml"), Source: SourceDefault})
+	}
+	seen := map[string]bool{}
+	for _, fd := range feeds {
+		if fd.URL == "" || seen[fd.URL] || len(seen) == maxFeeds {
+			continue
+		}
+		seen[fd.URL] = true
+		files = append(files, File{Kind: KindFeed, URL: fd.URL, Source: SourceLinked,
+			Fields: map[string]string{"declared_type": fd.Type}})
+	}
+	files = append(files, File{Kind: KindSecurityTxt, URL: at("/.well-known/security.txt"), Source: SourceDefault})
+	if manifest != "" {
+		files = append(files, File{Kind: KindManifest, URL: manifest, Source: SourceLinked})
+	} else {
+		files = append(files, File{Kind: KindManifest, URL: at("/manifest.json"), Source: SourceDefault})
+	}
+	files = append(files, File{Kind: KindAdsTxt, URL: at("/ads.txt"), Source: SourceDefault})
+
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i := range files {
+		wg.Add(1)
+		go func(file *File) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				file.Err = "context cancelled"
+				return
+			}
+			fetchFile(ctx, f, file, now)
+		}(&files[i])
+	}
+	wg.Wait()
+	return append([]File{robots}, files...)
+}
+
+// fetchFile fetches and checks one file, and returns its content when it
+// is present.
+func fetchFile(ctx context.Context, f Fetcher, file *File, now time.Time) []byte {
+	resp, body, err := f.Get(ctx, file.URL)
+	if err != nil {
+		slog.Warn("site file fetch failed", "url", file.URL, "err", err)
+		file.Err = err.Error()
+		return nil
+	}
+	defer resp.Body.Close()
+	file.Status = resp.StatusCode
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		file.Err = fmt.Sprintf("status %d", resp.StatusCode)
+		return nil
+	}
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mt == "text/html" && file.Source == SourceDefault {
+		// Many servers answer every path with an HTML page; at a
+		// conventional location that means the file does not exist.
+		return nil
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		file.Err = err.Error()
+		return nil
+	}
+
+	file.Present = true
+	if file.Fields == nil {
+		file.Fields = map[string]string{}
+	}
+	if mt == "text/html" {
+		file.problem("is served as text/html")
+	}
+	switch file.Kind {
+	case KindRobots:
+		checkRobots(file, data)
+	case KindSitemap:
+		checkSitemap(file, data, f.MaxBytes())
+	case KindFeed:
+		checkFeed(file, data)
+	case KindSecurityTxt:
+		if mt != "" && mt != "text/plain" {
+			file.problem("served as %s, not text/plain", mt)
+		}
+		checkSecurityTxt(file, data, now)
+	case KindManifest:
+		checkManifest(file, data)
+	case KindAdsTxt:
+		checkAdsTxt(file, data)
+	}
+	file.Valid = len(file.Problems) == 0
+	return data
+}
+
+// lines iterates over the lines of a text file, numbered from 1, without
+// comments and surrounding whitespace. Blank lines are skipped.
+func lines(data []byte, fn func(n int, line string)) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			fn(n, line)
+		}
+	}
+}
+
+func checkRobots(file *File, data []byte) {
+	agents := 0
+	lines(data, func(_ int, line string) {
+		if k, _, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(k), "user-agent") {
+			agents++
+		}
+	})
+	sitemaps := robotsSitemaps(data)
+	file.Fields["user_agents"] = strconv.Itoa(agents)
+	file.Fields["sitemaps"] = strconv.Itoa(len(sitemaps))
+	if agents == 0 && len(sitemaps) == 0 {
+		file.problem("has no User-agent groups or Sitemap lines")
+	}
+}
+
+// robotsSitemaps returns the absolute sitemap URLs declared in robots.txt.
+func robotsSitemaps(data []byte) []string {
+	var out []string
+	lines(data, func(_ int, line string) {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(k), "sitemap") {
+			return
+		}
+		if u, err := url.Parse(strings.TrimSpace(v)); err == nil && u.IsAbs() {
+			out = append(out, u.String())
+		}
+	})
+	return out
+}
+
+// errTooLarge is returned by gunzip for content that decompresses to more
+// than its limit.
+var errTooLarge = errors.New("decompressed content exceeds the size limit")
+
+// gunzip decompresses data when it starts with the gzip magic number, as
+// sitemap.xml.gz files served without a Content-Encoding do. Output beyond
+// limit bytes is not read.
+func gunzip(data []byte, limit int64) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(zr, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > limit {
+		return nil, errTooLarge
+	}
+	return out, nil
+}
+
+// Findings reports broken links to site files, invalid files, and a
+// missing sitemap or security.txt.
+func Findings(files []File) []contract.Finding {
+	var findings []contract.Finding
+	present := map[string]bool{}
+	for _, f := range files {
+		code := strings.ReplaceAll(f.Kind, ".", "_")
+		switch {
+		case f.Present:
+			present[f.Kind] = true
+			if len(f.Problems) > 0 {
+				msg := fmt.Sprintf("%s %s", f.URL, f.Problems[0])
+				if len(f.Problems) > 1 {
+					msg += fmt.Sprintf(" (and %d more problem(s))", len(f.Problems)-1)
+				}
+				findings = append(findings, contract.Finding{Code: "invalid_" + code, Severity: contract.SeverityWarning,
+					Message: msg, Element: f.URL})
+			}
+		case f.Source != SourceDefault:
+			reason := f.Err
+			if reason == "" {
+				reason = fmt.Sprintf("status %d", f.Status)
+			}
+			findings = append(findings, contract.Finding{Code: "broken_" + code, Severity: contract.SeverityWarning,
+				Message: fmt.Sprintf("%s %s from %s cannot be fetched: %s", f.Kind, f.URL, f.Source, reason),
+				Element: f.URL})
+		}
+	}
+	if !present[KindSitemap] {
+		findings = append(findings, contract.Finding{Code: "no_sitemap", Severity: contract.SeverityInfo,
+			Message: "no sitemap was found in robots.txt or at /sitemap.xml"})
+	}
+	if !present[KindSecurityTxt] {
+		findings = append(findings, contract.Finding{Code: "no_security_txt", Severity: contract.SeverityInfo,
+			Message: "no /.well-known/security.txt tells researchers how to report vulnerabilities"})
+	}
+	return findings
+}
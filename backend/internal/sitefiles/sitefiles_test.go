@@ -0,0 +1,153 @@
+package sitefiles_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/fetch"
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/sitefiles"
+)
+
+var now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestDiscover(t *testing.T) {
+	mux := http.NewServeMux()
+	var base string
+	serve := func(path, ct, body string) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", ct)
+			_, _ = w.Write([]byte(strings.ReplaceAll(body, "BASE", base)))
+		})
+	}
+	serve("/robots.txt", "text/plain", "User-agent: *\nDisallow: /admin\nSitemap: BASE/maps/index.xml\n")
+	serve("/maps/index.xml", "application/xml", `<?xml version="1.0"?>
+		<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+		  <sitemap><loc>BASE/maps/a.xml</loc></sitemap><sitemap><loc>BASE/maps/b.xml</loc></sitemap>
+		</sitemapindex>`)
+	serve("/feed.xml", "application/rss+xml", `<?xml version="1.0" encoding="ISO-8859-1"?>
+		<rss version="2.0"><channel><title>Caf`+"\xe9"+` news</title><item><title>a</title></item><item><title>b</title></item></channel></rss>`)
+	serve("/atom.xml", "application/atom+xml", `<feed xmlns="http://www.w3.org/2005/Atom"><entry><title>x</title></entry></feed>`)
+	serve("/.well-known/security.txt", "text/plain", "# Report issues\nContact: mailto:security@example.com\nExpires: 2025-06-30T00:00:00Z\n")
+	serve("/ads.txt", "text/plain", "contact=ads@example.com\ngoogle.com, pub-123, DIRECT, f08c47fec0942fa0\nexample.net, 42, RESELLER\nbroken line\n")
+	serve("/", "text/html", "<html>catch-all</html>")
+	mux.HandleFunc("/missing.webmanifest", http.NotFound)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	base = ts.URL
+
+	f := fetch.New(5*time.Second, 2, 1<<20)
+	f.AllowLocal()
+	page, _ := url.Parse(ts.URL + "/blog/post")
+	feeds := []parser.Feed{
+		{Type: "application/rss+xml", URL: ts.URL + "/feed.xml"},
+		{Type: "application/atom+xml", URL: ts.URL + "/atom.xml"},
+	}
+	files := sitefiles.Discover(context.Background(), f, page, feeds, ts.URL+"/missing.webmanifest", 4, now)
+
+	byURL := map[string]sitefiles.File{}
+	for _, file := range files {
+		byURL[strings.TrimPrefix(file.URL, ts.URL)] = file
+	}
+	check := func(path string, present, valid bool, fields map[string]string) {
+		t.Helper()
+		file, ok := byURL[path]
+		if !ok {
+			t.Fatalf("%s was not discovered: %+v", path, files)
+		}
+		if file.Present != present || file.Valid != valid {
+			t.Errorf("%s: expected present=%v valid=%v, got %+v", path, present, valid, file)
+		}
+		for k, v := range fields {
+			if file.Fields[k] != v {
+				t.Errorf("%s: expected %s=%q, got %q", path, k, v, file.Fields[k])
+			}
+		}
+	}
+	check("/robots.txt", true, true, map[string]string{"sitemaps": "1", "user_agents": "1"})
+	check("/maps/index.xml", true, true, map[string]string{"type": "sitemapindex", "sitemaps": "2"})
+	check("/feed.xml", true, true, map[string]string{"format": "rss", "title": "Café news", "items": "2"})
+	check("/atom.xml", true, false, map[string]string{"format": "atom", "items": "1"})
+	check("/.well-known/security.txt", true, false, map[string]string{"contact": "mailto:security@example.com"})
+	check("/missing.webmanifest", false, false, nil)
+	check("/ads.txt", true, false, map[string]string{"records": "2", "direct": "1", "reseller": "1"})
+	if _, ok := byURL["/sitemap.xml"]; ok {
+		t.Error("expected /sitemap.xml not to be tried when robots.txt declares a sitemap")
+	}
+
+	codes := map[string]bool{}
+	for _, fd := range sitefiles.Findings(files) {
+		codes[fd.Code] = true
+	}
+	for _, code := range []string{"invalid_feed", "invalid_security_txt", "invalid_ads_txt", "broken_manifest"} {
+		if !codes[code] {
+			t.Errorf("expected a %s finding, got %v", code, codes)
+		}
+	}
+	if codes["no_sitemap"] || codes["no_security_txt"] {
+		t.Errorf("unexpected findings %v", codes)
+	}
+}
+
+func TestDiscover_CatchAllIsAbsent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>Welcome</html>"))
+	}))
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 2, 1<<20)
+	f.AllowLocal()
+	page, _ := url.Parse(ts.URL)
+	files := sitefiles.Discover(context.Background(), f, page, nil, "", 4, now)
+
+	for _, file := range files {
+		if file.Present || file.Err != "" {
+			t.Errorf("expected %s to be absent, got %+v", file.URL, file)
+		}
+	}
+	codes := map[string]bool{}
+	for _, fd := range sitefiles.Findings(files) {
+		codes[fd.Code] = true
+	}
+	if len(codes) != 2 || !codes["no_sitemap"] || !codes["no_security_txt"] {
+		t.Errorf("expected only the missing sitemap and security.txt, got %v", codes)
+	}
+}
+
+func TestDiscover_GzipSitemapIsCapped(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`))
+	_, _ = zw.Write(bytes.Repeat([]byte(" "), 2<<20))
+	_, _ = zw.Write([]byte(`</urlset>`))
+	_ = zw.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-gzip")
+		_, _ = w.Write(gz.Bytes())
+	})
+	mux.HandleFunc("/", http.NotFound)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	f := fetch.New(5*time.Second, 2, 1<<20)
+	f.AllowLocal()
+	page, _ := url.Parse(ts.URL)
+	for _, file := range sitefiles.Discover(context.Background(), f, page, nil, "", 4, now) {
+		if file.Kind != sitefiles.KindSitemap {
+			continue
+		}
+		if !file.Present || file.Valid || len(file.Problems) != 1 || !strings.Contains(file.Problems[0], "decompresses to more than 1048576 bytes") {
+			t.Errorf("expected the oversized sitemap to be reported, got %+v", file)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package textstats computes word counts, readability scores and frequent
+// terms for the visible text of a page.
+package textstats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultTopTerms is the number of terms reported when the caller does not
+// ask for a specific number.
+const DefaultTopTerms = 10
+
+// MaxTopTerms bounds the number of terms reported.
+const MaxTopTerms = 50
+
+// Stats describes a page's visible text.
+type Stats struct {
+	Words     int
+	Sentences int
+	Syllables int
+	// TextHTMLRatio is the size of the visible text relative to the HTML
+	// document, between 0 and 1.
+	TextHTMLRatio float64
+	// English reports whether the readability scores and stop-word list
+	// apply. The Flesch formulas are calibrated for English only.
+	English            bool
+	FleschReadingEase  float64
+	FleschKincaidGrade float64
+	TopTerms           []Term
+}
+
+// Term is a frequent word and the number of times it occurs.
+type Term struct {
+	Term  string
+	Count int
+}
+
+// Analyze computes statistics for text, the visible text of an HTML document
+// of htmlBytes bytes. Each line is a block of text, such as a heading or a
+// paragraph, and ends any sentence left open in it. lang is the document
+// language; an empty lang is assumed to be English. topN terms are reported,
+// after stop-word removal for English text.
+func Analyze(text, lang string, htmlBytes int64, topN int) Stats {
+	st := Stats{English: isEnglish(lang)}
+	if htmlBytes > 0 {
+		st.TextHTMLRatio = round(math.Min(float64(len(text))/float64(htmlBytes), 1), 3)
+	}
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(text, "\n") {
+		inSentence := false
+		for _, tok := range strings.Fields(line) {
+			word := strings.TrimFunc(tok, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			})
+			if word == "" {
+				continue
+			}
+			st.Words++
+			st.Syllables += syllables(word)
+			inSentence = true
+			if endsSentence(tok) {
+				st.Sentences++
+				inSentence = false
+			}
+			if term, ok := normalizeTerm(word); ok && !(st.English && stopWords[term]) {
+				counts[term]++
+			}
+		}
+		if inSentence {
+			st.Sentences++
+		}
+	}
+
+	if st.English && st.Words > 0 {
+		wps := float64(st.Words) / float64(st.Sentences)
+		spw := float64(st.Syllables) / float64(st.Words)
+		st.FleschReadingEase = round(206.835-1.015*wps-84.6*spw, 1)
+		st.FleschKincaidGrade = round(0.39*wps+11.8*spw-15.59, 1)
+	}
+	st.TopTerms = topTerms(counts, topN)
+	return st
+}
+
+func isEnglish(lang string) bool {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	return lang == "" || lang == "en" || strings.HasPrefix(lang, "en-") || strings.HasPrefix(lang, "en_")
+}
+
+// endsSentence reports whether the token ends with sentence punctuation,
+// ignoring closing quotes and brackets.
+func endsSentence(tok string) bool {
+	tok = strings.TrimRight(tok, `"'”’)]»`)
+	return strings.HasSuffix(tok, ".") || strings.HasSuffix(tok, "!") ||
+		strings.HasSuffix(tok, "?") || strings.HasSuffix(tok, "…")
+}
+
+// normalizeTerm lower-cases a word and drops possessives. Numbers and single
+// letters are not terms.
+func normalizeTerm(word string) (string, bool) {
+	word = strings.ReplaceAll(strings.ToLower(word), "’", "'")
+	word = strings.TrimSuffix(word, "'s")
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return word, letters > 1
+}
+
+// syllables estimates the syllable count of an English word by counting
+// vowel groups, discounting a silent final e. Numbers count as one.
+func syllables(word string) int {
+	w := strings.ToLower(word)
+	n := 0
+	prevVowel := false
+	for _, r := range w {
+		v := strings.ContainsRune("aeiouy", r)
+		if v && !prevVowel {
+			n++
+		}
+		prevVowel = v
+	}
+	if n > 1 && strings.HasSuffix(w, "e") && !strings.HasSuffix(w, "le") && !strings.HasSuffix(w, "ee") {
+		n--
+	}
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+func topTerms(counts map[string]int, n int) []Term {
+	if n <= 0 {
+		n = DefaultTopTerms
+	}
+	if n > MaxTopTerms {
+		n = MaxTopTerms
+	}
+	terms := make([]Term, 0, len(counts))
+	for t, c := range counts {
+		terms = append(terms, Term{Term: t, Count: c})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,77 @@
+package textstats_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/textstats"
+)
+
+func TestAnalyze_English(t *testing.T) {
+	text := "The cat sat on the mat. The cat ran!"
+
+	st := textstats.Analyze(text, "en-GB", int64(len(text))*4, 3)
+
+	if st.Words != 9 || st.Sentences != 2 || st.Syllables != 9 {
+		t.Errorf("expected 9 words, 2 sentences, 9 syllables; got %d, %d, %d", st.Words, st.Sentences, st.Syllables)
+	}
+	if st.TextHTMLRatio != 0.25 {
+		t.Errorf("expected ratio 0.25, got %v", st.TextHTMLRatio)
+	}
+	if !st.English || st.FleschReadingEase != 117.7 || st.FleschKincaidGrade != -2.0 {
+		t.Errorf("unexpected readability: english=%v ease=%v grade=%v", st.English, st.FleschReadingEase, st.FleschKincaidGrade)
+	}
+
+	want := []textstats.Term{{Term: "cat", Count: 2}, {Term: "mat", Count: 1}, {Term: "ran", Count: 1}}
+	if !reflect.DeepEqual(st.TopTerms, want) {
+		t.Errorf("expected top terms %v, got %v", want, st.TopTerms)
+	}
+}
+
+func TestAnalyze_SentencesAndTerms(t *testing.T) {
+	st := textstats.Analyze(`He said "stop." Then — nothing. Google's search, Google's ads, 2024 and a final clause`, "", 0, 0)
+
+	if st.Words != 14 {
+		t.Errorf("expected 14 words, got %d", st.Words)
+	}
+	if st.Sentences != 3 {
+		t.Errorf("expected 3 sentences, got %d", st.Sentences)
+	}
+	if st.TextHTMLRatio != 0 {
+		t.Errorf("expected no ratio without an HTML size, got %v", st.TextHTMLRatio)
+	}
+	if len(st.TopTerms) == 0 || st.TopTerms[0] != (textstats.Term{Term: "google", Count: 2}) {
+		t.Errorf("expected google to be the top term, got %v", st.TopTerms)
+	}
+	for _, term := range st.TopTerms {
+		if term.Term == "2024" || term.Term == "and" || term.Term == "a" {
+			t.Errorf("unexpected term %q", term.Term)
+		}
+	}
+}
+
+func TestAnalyze_LinesEndSentences(t *testing.T) {
+	st := textstats.Analyze("Products\nWidgets are small. We sell widgets\nContact us", "en", 0, 0)
+	if st.Words != 9 || st.Sentences != 4 {
+		t.Errorf("expected 9 words in 4 sentences, got %d in %d", st.Words, st.Sentences)
+	}
+}
+
+func TestAnalyze_NonEnglish(t *testing.T) {
+	st := textstats.Analyze("Der Hund und die Katze. Der Hund schläft.", "de", 100, 2)
+
+	if st.English || st.FleschReadingEase != 0 {
+		t.Errorf("expected no English readability scores, got %+v", st)
+	}
+	want := []textstats.Term{{Term: "der", Count: 2}, {Term: "hund", Count: 2}}
+	if !reflect.DeepEqual(st.TopTerms, want) {
+		t.Errorf("expected %v without stop-word removal, got %v", want, st.TopTerms)
+	}
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	st := textstats.Analyze("", "", 1000, 0)
+	if st.Words != 0 || st.Sentences != 0 || st.FleschReadingEase != 0 || len(st.TopTerms) != 0 {
+		t.Errorf("expected empty stats, got %+v", st)
+	}
+}
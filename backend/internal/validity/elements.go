@@ -0,0 +1,63 @@
+package validity
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+// knownElements are the elements of the HTML standard, including obsolete
+// ones the tree builder still knows about.
+var knownElements = set(
+	"a", "abbr", "address", "area", "article", "aside", "audio", "b", "base",
+	"bdi", "bdo", "blockquote", "body", "br", "button", "canvas", "caption",
+	"cite", "code", "col", "colgroup", "data", "datalist", "dd", "del",
+	"details", "dfn", "dialog", "div", "dl", "dt", "em", "embed", "fieldset",
+	"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5",
+	"h6", "head", "header", "hgroup", "hr", "html", "i", "iframe", "img",
+	"input", "ins", "kbd", "label", "legend", "li", "link", "main", "map",
+	"mark", "math", "menu", "meta", "meter", "nav", "noscript", "object", "ol",
+	"optgroup", "option", "output", "p", "param", "picture", "pre", "progress",
+	"q", "rp", "rt", "ruby", "s", "samp", "script", "search", "section",
+	"select", "slot", "small", "source", "span", "strong", "style", "sub",
+	"summary", "sup", "svg", "table", "tbody", "td", "template", "textarea",
+	"tfoot", "th", "thead", "time", "title", "tr", "track", "u", "ul", "var",
+	"video", "wbr",
+	// Obsolete, reported by the conformance checks instead.
+	"acronym", "applet", "basefont", "bgsound", "big", "blink", "center",
+	"dir", "font", "frame", "frameset", "image", "isindex", "keygen",
+	"listing", "marquee", "menuitem", "multicol", "nextid", "nobr", "noembed",
+	"noframes", "plaintext", "rb", "rtc", "spacer", "strike", "tt", "xmp",
+)
+
+var voidElements = set(
+	"area", "base", "basefont", "bgsound", "br", "col", "embed", "frame", "hr",
+	"image", "img", "input", "keygen", "link", "meta", "param", "source",
+	"track", "wbr",
+)
+
+// headTags may appear in <head>; any other start tag begins the body.
+var headTags = set("base", "link", "meta", "noscript", "script", "style", "template", "title")
+
+// closesP are the start tags that close an open <p> element.
+var closesP = set(
+	"address", "article", "aside", "blockquote", "center", "details", "dialog",
+	"dir", "div", "dl", "fieldset", "figcaption", "figure", "footer", "form",
+	"h1", "h2", "h3", "h4", "h5", "h6", "header", "hgroup", "hr", "li",
+	"listing", "main", "menu", "nav", "ol", "p", "plaintext", "pre", "search",
+	"section", "summary", "table", "ul", "xmp", "dd", "dt",
+)
+
+// tableElements are closed by their end tags across open cells.
+var tableElements = set("caption", "colgroup", "table", "tbody", "td", "tfoot", "th", "thead", "tr")
+
+// Element scopes, as in the tree construction algorithm: a search for an
+// open element stops at any of these.
+var (
+	defaultScope  = set("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template")
+	buttonScope   = set("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template", "button")
+	listItemScope = set("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template", "ol", "ul")
+	tableScope    = set("html", "table", "template")
+)
@@ -0,0 +1,318 @@
+// Package validity reports common HTML authoring errors without calling an
+// external validator. It makes one pass over an html.Tokenizer, keeping a
+// simplified stack of open elements to see where the tree builder would
+// close, drop or re-parent elements, and records the line and column of each
+// problem.
+package validity
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Problem codes.
+const (
+	CodeDuplicateID      = "duplicate-id"
+	CodeMultipleTitle    = "multiple-title"
+	CodeMultipleMain     = "multiple-main"
+	CodeTitleOutsideHead = "title-outside-head"
+	CodeAnchorReparent   = "anchor-reparented"
+	CodeUnknownElement   = "unknown-element"
+	CodeStrayEndTag      = "stray-end-tag"
+)
+
+// MaxProblems bounds the problems kept in a Report; Total keeps counting.
+const MaxProblems = 100
+
+// Problem is an authoring error at a 1-based line and column. Columns count
+// characters, not bytes.
+type Problem struct {
+	Code    string
+	Message string
+	Line    int
+	Column  int
+}
+
+// Report lists the problems found, in document order.
+type Report struct {
+	Problems []Problem
+	Total    int
+}
+
+// Check tokenizes r and reports the problems found. r is always read to the
+// end, so Check can consume one side of a pipe or tee.
+func Check(r io.Reader) (Report, error) {
+	c := &checker{line: 1, col: 1, ids: map[string]int{}, split: map[string]int{}}
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				_, _ = io.Copy(io.Discard, r)
+				return c.rep, err
+			}
+			return c.rep, nil
+		}
+
+		// Text and TagName rewrite the token in place, so the position
+		// is advanced over the raw bytes first.
+		line, col := c.line, c.col
+		c.advance(z.Raw())
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			c.startTag(strings.ToLower(tok.Data), tok.Attr, tt == html.SelfClosingTagToken, line, col)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			c.endTag(strings.ToLower(string(name)), line, col)
+		case html.TextToken:
+			if !c.inBody && len(c.stack) == 0 && strings.TrimSpace(string(z.Text())) != "" {
+				c.inBody = true
+			}
+		}
+	}
+}
+
+type checker struct {
+	rep       Report
+	line, col int
+	// stack holds the open elements, innermost last. html, head and body
+	// are left out: the tree builder never closes them early.
+	stack []string
+	// inBody is set once content that belongs in <body> has started.
+	inBody bool
+
+	// split counts end tags still to come for elements the parser closed
+	// when it re-parented a block; they are part of that problem.
+	split map[string]int
+
+	ids    map[string]int
+	titles int
+	mains  int
+}
+
+func (c *checker) advance(raw []byte) {
+	for len(raw) > 0 {
+		r, size := utf8.DecodeRune(raw)
+		raw = raw[size:]
+		switch r {
+		case '\r':
+			if len(raw) > 0 && raw[0] == '\n' {
+				raw = raw[1:]
+			}
+			fallthrough
+		case '\n':
+			c.line++
+			c.col = 1
+		default:
+			c.col++
+		}
+	}
+}
+
+func (c *checker) report(code string, line, col int, format string, args ...any) {
+	c.rep.Total++
+	if len(c.rep.Problems) < MaxProblems {
+		c.rep.Problems = append(c.rep.Problems, Problem{
+			Code:    code,
+			Message: fmt.Sprintf(format, args...),
+			Line:    line,
+			Column:  col,
+		})
+	}
+}
+
+// foreign reports whether the current insertion point is inside <svg> or
+// <math>, which have their own vocabularies.
+func (c *checker) foreign() bool {
+	for _, t := range c.stack {
+		if t == "svg" || t == "math" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) startTag(tag string, attrs []html.Attribute, selfClosing bool, line, col int) {
+	for _, a := range attrs {
+		if strings.ToLower(a.Key) != "id" || a.Val == "" {
+			continue
+		}
+		if first, ok := c.ids[a.Val]; ok {
+			c.report(CodeDuplicateID, line, col, "id %q is already used on line %d", a.Val, first)
+		} else {
+			c.ids[a.Val] = line
+		}
+	}
+
+	if c.foreign() {
+		if !selfClosing {
+			c.stack = append(c.stack, tag)
+		}
+		return
+	}
+
+	switch tag {
+	case "html", "head":
+		return
+	case "body":
+		c.inBody = true
+		return
+	}
+	if !headTags[tag] {
+		c.inBody = true
+	}
+
+	if !knownElements[tag] && !strings.Contains(tag, "-") {
+		c.report(CodeUnknownElement, line, col, "<%s> is not an HTML element; custom elements need a hyphen in their name", tag)
+	}
+	switch tag {
+	case "title":
+		c.titles++
+		if c.titles > 1 {
+			c.report(CodeMultipleTitle, line, col, "the document has more than one <title>")
+		}
+		if c.inBody {
+			c.report(CodeTitleOutsideHead, line, col, "<title> belongs in <head>")
+		}
+	case "main":
+		if _, hidden := attrValue(attrs, "hidden"); !hidden {
+			c.mains++
+			if c.mains > 1 {
+				c.report(CodeMultipleMain, line, col, "the document has more than one visible <main>")
+			}
+		}
+	case "a":
+		if c.inScope("a", defaultScope) {
+			c.report(CodeAnchorReparent, line, col, "<a> inside another <a>; the parser closes the outer anchor")
+			c.popTo("a")
+		}
+	}
+
+	c.closeImplied(tag, line, col)
+	if !selfClosing && !voidElements[tag] {
+		c.stack = append(c.stack, tag)
+	}
+}
+
+// closeImplied pops the elements a start tag closes implicitly, such as an
+// open <p> before a block or the previous <li>.
+func (c *checker) closeImplied(tag string, line, col int) {
+	if i := c.find("p", buttonScope); closesP[tag] && i >= 0 {
+		for _, open := range c.stack[i+1:] {
+			if open == "a" {
+				c.report(CodeAnchorReparent, line, col,
+					"<%s> inside <a> closes the enclosing <p>; the parser splits the anchor and re-parents the block", tag)
+				for _, closed := range c.stack[i:] {
+					c.split[closed]++
+				}
+				break
+			}
+		}
+		c.stack = c.stack[:i]
+	}
+	switch tag {
+	case "li":
+		c.popIfInScope(listItemScope, "li")
+	case "dd", "dt":
+		c.popIfInScope(defaultScope, "dd", "dt")
+	case "option", "optgroup":
+		if n := len(c.stack); n > 0 && c.stack[n-1] == "option" {
+			c.stack = c.stack[:n-1]
+		}
+	case "tr":
+		c.popIfInScope(tableScope, "tr")
+	case "td", "th":
+		c.popIfInScope(tableScope, "td", "th")
+	case "tbody", "thead", "tfoot":
+		c.popIfInScope(tableScope, "tbody", "thead", "tfoot")
+	}
+}
+
+func (c *checker) endTag(tag string, line, col int) {
+	switch tag {
+	case "html", "body":
+		return
+	case "head":
+		c.inBody = true
+		return
+	}
+	if voidElements[tag] {
+		c.report(CodeStrayEndTag, line, col, "</%s> is a void element and has no end tag", tag)
+		return
+	}
+
+	scope := defaultScope
+	switch {
+	case c.foreign():
+		scope = nil
+	case tag == "p":
+		scope = buttonScope
+	case tag == "li":
+		scope = listItemScope
+	case tableElements[tag]:
+		scope = tableScope
+	}
+	i := c.find(tag, scope)
+	if i < 0 && c.split[tag] > 0 {
+		c.split[tag]--
+		return
+	}
+	if i < 0 {
+		c.report(CodeStrayEndTag, line, col, "</%s> has no matching open <%s>", tag, tag)
+		return
+	}
+	c.stack = c.stack[:i]
+}
+
+// find returns the index of the innermost open element named tag, or -1
+// when there is none or a scope boundary comes first.
+func (c *checker) find(tag string, scope map[string]bool) int {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i] == tag {
+			return i
+		}
+		if scope[c.stack[i]] {
+			return -1
+		}
+	}
+	return -1
+}
+
+func (c *checker) inScope(tag string, scope map[string]bool) bool {
+	return c.find(tag, scope) >= 0
+}
+
+func (c *checker) popTo(tag string) {
+	if i := c.find(tag, nil); i >= 0 {
+		c.stack = c.stack[:i]
+	}
+}
+
+func (c *checker) popIfInScope(scope map[string]bool, tags ...string) {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		for _, t := range tags {
+			if c.stack[i] == t {
+				c.stack = c.stack[:i]
+				return
+			}
+		}
+		if scope[c.stack[i]] {
+			return
+		}
+	}
+}
+
+func attrValue(attrs []html.Attribute, key string) (string, bool) {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
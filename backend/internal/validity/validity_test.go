@@ -0,0 +1,84 @@
+package validity_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/validity"
+)
+
+func check(t *testing.T, page string) []validity.Problem {
+	t.Helper()
+	rep, err := validity.Check(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Total != len(rep.Problems) {
+		t.Errorf("expected Total %d to match the problems, got %d", len(rep.Problems), rep.Total)
+	}
+	return rep.Problems
+}
+
+func TestCheck_Valid(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html><head><title>Fine</title><meta charset="utf-8"></head>
+<body>
+  <main id="a"><p>One<p>Two</p><a href="/x"><div>block link</div></a></main>
+  <main hidden></main>
+  <ul><li>a<li>b</ul>
+  <table><tr><td><b>x</td><td>y</table>
+  <my-widget></my-widget><img src="a.png"><br/>
+  <svg><title>Icon</title><foo/><rect></rect></svg>
+</body></html>`
+	if got := check(t, page); len(got) != 0 {
+		t.Errorf("expected no problems, got %+v", got)
+	}
+}
+
+func TestCheck_Problems(t *testing.T) {
+	page := "<html><head><title>A</title></head>\n" + // 1
+		"<body id=\"top\">\n" + // 2
+		"  <div id=\"top\"></div></span>\n" + // 3
+		"  <title>B</title>\n" + // 4
+		"  <main></main><main></main>\n" + // 5
+		"  <p><a href=\"/\">x<div>y</div></a></p>\n" + // 6
+		"  <a href=\"/1\"><a href=\"/2\">z</a>\n" + // 7
+		"  <widget>é<blink></blink></widget></br>\n" // 8
+	got := check(t, page)
+
+	want := []validity.Problem{
+		{Code: validity.CodeDuplicateID, Line: 3, Column: 3},
+		{Code: validity.CodeStrayEndTag, Line: 3, Column: 23},
+		{Code: validity.CodeMultipleTitle, Line: 4, Column: 3},
+		{Code: validity.CodeTitleOutsideHead, Line: 4, Column: 3},
+		{Code: validity.CodeMultipleMain, Line: 5, Column: 16},
+		{Code: validity.CodeAnchorReparent, Line: 6, Column: 19},
+		{Code: validity.CodeAnchorReparent, Line: 7, Column: 16},
+		{Code: validity.CodeUnknownElement, Line: 8, Column: 3},
+		{Code: validity.CodeStrayEndTag, Line: 8, Column: 36},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d problems, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Code != w.Code || g.Line != w.Line || g.Column != w.Column {
+			t.Errorf("problem %d: expected %s at %d:%d, got %s at %d:%d (%s)",
+				i, w.Code, w.Line, w.Column, g.Code, g.Line, g.Column, g.Message)
+		}
+	}
+	if !strings.Contains(got[0].Message, `"top"`) || !strings.Contains(got[0].Message, "line 2") {
+		t.Errorf("unexpected duplicate id message %q", got[0].Message)
+	}
+}
+
+func TestCheck_CapsProblems(t *testing.T) {
+	page := strings.Repeat("</span>", validity.MaxProblems+5)
+	rep, err := validity.Check(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Problems) != validity.MaxProblems || rep.Total != validity.MaxProblems+5 {
+		t.Errorf("expected %d of %d problems, got %d of %d", validity.MaxProblems, validity.MaxProblems+5, len(rep.Problems), rep.Total)
+	}
+}
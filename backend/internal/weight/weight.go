@@ -0,0 +1,123 @@
+// Package weight estimates the transfer size of a page from the sizes its
+// servers report for the document and its scripts, stylesheets, fonts and
+// images.
+package weight
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+)
+
+// Resource types counted towards the page weight.
+const (
+	TypeDocument   = "document"
+	TypeScript     = "script"
+	TypeStylesheet = "stylesheet"
+	TypeFont       = "font"
+	TypeImage      = "image"
+)
+
+var extTypes = map[string]string{
+	".js": TypeScript, ".mjs": TypeScript,
+	".css":  TypeStylesheet,
+	".woff": TypeFont, ".woff2": TypeFont, ".ttf": TypeFont, ".otf": TypeFont, ".eot": TypeFont,
+	".png": TypeImage, ".jpg": TypeImage, ".jpeg": TypeImage, ".gif": TypeImage, ".webp": TypeImage,
+	".avif": TypeImage, ".svg": TypeImage, ".ico": TypeImage, ".bmp": TypeImage,
+}
+
+// Type returns the weight type of a resource, or "" when it is not
+// counted. Preloads and CSS url() references are typed by extension; CSS
+// references without a known extension count as images.
+func Type(r parser.Resource) string {
+	switch r.Kind {
+	case parser.KindScript:
+		return TypeScript
+	case parser.KindStylesheet:
+		return TypeStylesheet
+	case parser.KindImage, parser.KindIcon:
+		return TypeImage
+	case parser.KindPreload, parser.KindCSS:
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return ""
+		}
+		if t, ok := extTypes[strings.ToLower(path.Ext(u.Path))]; ok {
+			return t
+		}
+		if r.Kind == parser.KindCSS {
+			return TypeImage
+		}
+	}
+	return ""
+}
+
+// Item is one request counted towards the page weight.
+type Item struct {
+	URL        string
+	Type       string
+	ThirdParty bool
+	// Bytes is -1 when the size is unknown.
+	Bytes int64
+}
+
+// Items returns one item per distinct URL among the counted resources, in
+// document order, with unknown sizes.
+func Items(resources []parser.Resource) []Item {
+	seen := map[string]bool{}
+	var out []Item
+	for _, r := range resources {
+		t := Type(r)
+		if t == "" || seen[r.URL] {
+			continue
+		}
+		seen[r.URL] = true
+		out = append(out, Item{URL: r.URL, Type: t, ThirdParty: r.ThirdParty, Bytes: -1})
+	}
+	return out
+}
+
+// Bucket is a request count and the bytes of those with a known size.
+type Bucket struct {
+	Requests int
+	Bytes    int64
+}
+
+func (b *Bucket) add(it Item) {
+	b.Requests++
+	if it.Bytes > 0 {
+		b.Bytes += it.Bytes
+	}
+}
+
+// Report is the estimated page weight. Unknown counts the requests whose
+// size could not be determined; they are left out of the byte totals.
+type Report struct {
+	Total      Bucket
+	ByType     map[string]Bucket
+	FirstParty Bucket
+	ThirdParty Bucket
+	Unknown    int
+}
+
+// Summarize totals items by type and by party.
+func Summarize(items []Item) Report {
+	rep := Report{ByType: map[string]Bucket{}}
+	for _, it := range items {
+		rep.Total.add(it)
+		b := rep.ByType[it.Type]
+		b.add(it)
+		rep.ByType[it.Type] = b
+		if it.ThirdParty {
+			rep.ThirdParty.add(it)
+		} else {
+			rep.FirstParty.add(it)
+		}
+		if it.Bytes < 0 {
+			rep.Unknown++
+		}
+	}
+	return rep
+}
@@ -0,0 +1,56 @@
+package weight_test
+
+import (
+	"testing"
+
+	"github.com/chanaka-withanage/page-analyzer/internal/parser"
+	"github.com/chanaka-withanage/page-analyzer/internal/weight"
+)
+
+func TestType(t *testing.T) {
+	cases := []struct {
+		r    parser.Resource
+		want string
+	}{
+		{parser.Resource{Kind: parser.KindScript, URL: "https://x.test/app"}, weight.TypeScript},
+		{parser.Resource{Kind: parser.KindIcon, URL: "https://x.test/favicon.ico"}, weight.TypeImage},
+		{parser.Resource{Kind: parser.KindPreload, URL: "https://x.test/f.WOFF2?v=1"}, weight.TypeFont},
+		{parser.Resource{Kind: parser.KindPreload, URL: "https://x.test/data.json"}, ""},
+		{parser.Resource{Kind: parser.KindCSS, URL: "https://x.test/font.ttf"}, weight.TypeFont},
+		{parser.Resource{Kind: parser.KindCSS, URL: "https://x.test/sprite"}, weight.TypeImage},
+		{parser.Resource{Kind: parser.KindIframe, URL: "https://x.test/embed"}, ""},
+	}
+	for _, tc := range cases {
+		if got := weight.Type(tc.r); got != tc.want {
+			t.Errorf("Type(%s %s) = %q, want %q", tc.r.Kind, tc.r.URL, got, tc.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	items := weight.Items([]parser.Resource{
+		{Kind: parser.KindScript, URL: "https://x.test/a.js"},
+		{Kind: parser.KindPreload, URL: "https://x.test/a.js"},
+		{Kind: parser.KindImage, URL: "https://cdn.test/b.png", ThirdParty: true},
+		{Kind: parser.KindMedia, URL: "https://x.test/v.mp4"},
+	})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 distinct items, got %+v", items)
+	}
+	items[0].Bytes = 1000
+	items = append(items, weight.Item{Type: weight.TypeDocument, Bytes: 500})
+
+	rep := weight.Summarize(items)
+	if rep.Total != (weight.Bucket{Requests: 3, Bytes: 1500}) {
+		t.Errorf("unexpected total %+v", rep.Total)
+	}
+	if rep.FirstParty != (weight.Bucket{Requests: 2, Bytes: 1500}) || rep.ThirdParty != (weight.Bucket{Requests: 1}) {
+		t.Errorf("unexpected party split %+v / %+v", rep.FirstParty, rep.ThirdParty)
+	}
+	if rep.ByType[weight.TypeImage].Requests != 1 || rep.ByType[weight.TypeScript].Bytes != 1000 {
+		t.Errorf("unexpected types %+v", rep.ByType)
+	}
+	if rep.Unknown != 1 {
+		t.Errorf("expected 1 unknown size, got %d", rep.Unknown)
+	}
+}
@@ -1,19 +1,533 @@
 package contract
 
+import "encoding/json"
+
 type AnalyzeParams struct {
 	URL                 string
 	FetchTimeoutSeconds int
+	// CheckHreflang fetches each hreflang alternate to verify that it links
+	// back and that its <html lang> matches.
+	CheckHreflang bool
+	// TopTerms is how many frequent terms to report for the page text. Zero
+	// uses the default.
+	TopTerms int
+	// ScanScripts downloads the start of external scripts that could not be
+	// identified from their URL, to look for library banner comments.
+	ScanScripts bool
+	// ConformanceTarget is the doctype obsolete markup is checked against:
+	// html5 (the default), html4-strict, html4-transitional, html4-frameset,
+	// the xhtml1- equivalents, or declared for the page's own doctype.
+	ConformanceTarget string
+	// FollowRefresh fetches the target of a meta refresh and analyzes it
+	// instead, up to a few hops.
+	FollowRefresh bool
+	// FetchImages requests each image to report its transfer size and flag
+	// oversized assets.
+	FetchImages bool
+	// CheckAssetCaching requests the page's static assets to audit their
+	// caching and compression headers alongside the document's.
+	CheckAssetCaching bool
+	// CheckSiteFiles fetches the site's feeds, sitemaps, robots.txt,
+	// security.txt, web app manifest and ads.txt and checks their structure.
+	CheckSiteFiles bool
+	// FollowRelations fetches the AMP counterpart or canonical page and
+	// walks the rel=next/prev chain to check that the pages agree.
+	FollowRelations bool
+	// Extract names CSS selectors to read from the page; the values come
+	// back in AnalyzeResult.Extracted under the same names.
+	Extract map[string]ExtractRule
+}
+
+// ExtractRule selects one user-defined field. Attr reads an attribute
+// instead of the element text; Multiple returns every match instead of the
+// first.
+type ExtractRule struct {
+	Selector string `json:"selector"`
+	Attr     string `json:"attr,omitempty"`
+	Multiple bool   `json:"multiple,omitempty"`
+}
+
+type ContentParams struct {
+	URL                 string
+	FetchTimeoutSeconds int
+}
+
+// SimilarityParams names the two pages to compare. Each side is either a
+// URL to fetch or the content digest of a stored result.
+type SimilarityParams struct {
+	A, B                SimilarityInput
+	FetchTimeoutSeconds int
+}
+
+type SimilarityInput struct {
+	URL    string
+	Digest *ContentDigest
+}
+
+// ContentResult is the main content of a page, as returned by /api/content.
+type ContentResult struct {
+	URL      string         `json:"url"`
+	Title    string         `json:"title"`
+	Byline   string         `json:"byline,omitempty"`
+	Markdown string         `json:"markdown"`
+	Images   []ContentImage `json:"images"`
+	Errors   []string       `json:"errors,omitempty"`
+}
+
+type ContentImage struct {
+	URL string `json:"url"`
+	Alt string `json:"alt,omitempty"`
 }
 
 type AnalyzeResult struct {
-	URL               string            `json:"url"`
-	HTMLVersion       string            `json:"html_version"`
-	Title             string            `json:"title"`
-	Headings          map[string]int    `json:"headings"`
-	LinksInternal     int               `json:"links_internal"`
-	LinksExternal     int               `json:"links_external"`
-	LinksInaccessible int               `json:"links_inaccessible"`
-	LoginFormPresent  bool              `json:"login_form_present"`
-	Warnings          []string          `json:"warnings,omitempty"`
-	Errors            []string          `json:"errors,omitempty"`
+	URL               string                     `json:"url"`
+	HTMLVersion       string                     `json:"html_version"`
+	BaseURL           string                     `json:"base_url"`
+	Title             string                     `json:"title"`
+	Headings          map[string]int             `json:"headings"`
+	LinksInternal     int                        `json:"links_internal"`
+	LinksExternal     int                        `json:"links_external"`
+	LinksInaccessible int                        `json:"links_inaccessible"`
+	LoginFormPresent  bool                       `json:"login_form_present"`
+	Login             *LoginInfo                 `json:"login,omitempty"`
+	Forms             []Form                     `json:"forms,omitempty"`
+	FormFindings      []Finding                  `json:"form_findings,omitempty"`
+	MixedContent      *MixedContentReport        `json:"mixed_content,omitempty"`
+	Hreflang          *HreflangReport            `json:"hreflang,omitempty"`
+	Text              *TextStats                 `json:"text,omitempty"`
+	JSLibraries       []JSLibrary                `json:"js_libraries,omitempty"`
+	Privacy           *PrivacyReport             `json:"privacy,omitempty"`
+	Exposure          *ExposureReport            `json:"exposure,omitempty"`
+	CSP               *CSPReport                 `json:"csp,omitempty"`
+	SiteFiles         *SiteFilesReport           `json:"site_files,omitempty"`
+	Relations         *RelationsReport           `json:"relations,omitempty"`
+	Conformance       *ConformanceReport         `json:"conformance,omitempty"`
+	Validity          *ValidityReport            `json:"validity,omitempty"`
+	Rendering         *RenderingReport           `json:"rendering,omitempty"`
+	Images            *ImageReport               `json:"images,omitempty"`
+	Weight            *PageWeight                `json:"weight,omitempty"`
+	Caching           *CachingReport             `json:"caching,omitempty"`
+	ContentDigest     *ContentDigest             `json:"content_digest,omitempty"`
+	Extracted         map[string]ExtractedField  `json:"extracted,omitempty"`
+	Resources         ResourceSummary            `json:"resources"`
+	Sections          map[string]json.RawMessage `json:"sections,omitempty"`
+	Warnings          []string                   `json:"warnings,omitempty"`
+	Errors            []string                   `json:"errors,omitempty"`
+}
+
+type ResourceSummary struct {
+	Total              int              `json:"total"`
+	FirstParty         int              `json:"first_party"`
+	ThirdParty         int              `json:"third_party"`
+	ByKind             map[string]int   `json:"by_kind"`
+	Inaccessible       int              `json:"inaccessible"`
+	InaccessibleByKind map[string]int   `json:"inaccessible_by_kind"`
+	Broken             []BrokenResource `json:"broken,omitempty"`
+}
+
+type BrokenResource struct {
+	URL        string `json:"url"`
+	Kind       string `json:"kind"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type LoginInfo struct {
+	Kind       string           `json:"kind"`
+	Confidence float64          `json:"confidence"`
+	Element    string           `json:"element"`
+	Reasons    []string         `json:"reasons"`
+	Candidates []LoginCandidate `json:"candidates,omitempty"`
+}
+
+type LoginCandidate struct {
+	Kind       string   `json:"kind"`
+	Confidence float64  `json:"confidence"`
+	Element    string   `json:"element"`
+	Reasons    []string `json:"reasons"`
+}
+
+type Form struct {
+	Element      string      `json:"element"`
+	Method       string      `json:"method"`
+	Action       string      `json:"action"`
+	Autocomplete string      `json:"autocomplete,omitempty"`
+	Inputs       []FormInput `json:"inputs"`
+	HasPassword  bool        `json:"has_password"`
+	HasCSRFToken bool        `json:"has_csrf_token"`
+}
+
+type FormInput struct {
+	Tag          string `json:"tag"`
+	Type         string `json:"type,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Autocomplete string `json:"autocomplete,omitempty"`
+}
+
+// Finding severities.
+const (
+	SeverityHigh    = "high"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+type Finding struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Element  string `json:"element,omitempty"`
+}
+
+// MixedContentReport lists http:// references on an https page. Active
+// content is blocked by browsers; passive content loads with a warning.
+type MixedContentReport struct {
+	Active  []MixedContentItem `json:"active"`
+	Passive []MixedContentItem `json:"passive"`
+}
+
+type MixedContentItem struct {
+	URL  string `json:"url"`
+	Kind string `json:"kind"`
+	Tag  string `json:"tag"`
+	Path string `json:"path"`
+}
+
+type HreflangReport struct {
+	Lang       string              `json:"lang,omitempty"`
+	Alternates []HreflangAlternate `json:"alternates"`
+	Findings   []Finding           `json:"findings,omitempty"`
+}
+
+// HreflangAlternate is one declared alternate. Reciprocal, Lang and
+// LangMatches are only set when the alternate was fetched.
+type HreflangAlternate struct {
+	Hreflang    string `json:"hreflang"`
+	URL         string `json:"url"`
+	Reciprocal  *bool  `json:"reciprocal,omitempty"`
+	Lang        string `json:"lang,omitempty"`
+	LangMatches *bool  `json:"lang_matches,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// TextStats describes the visible text of the page. The Flesch scores are
+// only reported for English pages.
+type TextStats struct {
+	Words              int         `json:"words"`
+	Sentences          int         `json:"sentences"`
+	TextHTMLRatio      float64     `json:"text_html_ratio"`
+	FleschReadingEase  *float64    `json:"flesch_reading_ease,omitempty"`
+	FleschKincaidGrade *float64    `json:"flesch_kincaid_grade,omitempty"`
+	TopTerms           []TermCount `json:"top_terms,omitempty"`
+}
+
+type TermCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// JSLibrary is a front-end library identified on the page, with the known
+// vulnerabilities of its version. Script is empty for inline scripts.
+type JSLibrary struct {
+	Name            string            `json:"name"`
+	Version         string            `json:"version"`
+	Detection       string            `json:"detection"`
+	Script          string            `json:"script,omitempty"`
+	Vulnerabilities []JSVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type JSVulnerability struct {
+	Severity  string   `json:"severity"`
+	CVEs      []string `json:"cves,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+	AtOrAbove string   `json:"at_or_above,omitempty"`
+	Below     string   `json:"below,omitempty"`
+	Info      []string `json:"info,omitempty"`
+}
+
+// PrivacyReport lists the third-party domains a page loads resources from,
+// grouped by registrable domain, the cookies set by the page response and
+// whether a consent-management platform is present. Categories counts
+// third-party domains per category.
+type PrivacyReport struct {
+	ThirdParties   []ThirdParty   `json:"third_parties"`
+	Categories     map[string]int `json:"categories"`
+	Cookies        []Cookie       `json:"cookies"`
+	ConsentManager ConsentManager `json:"consent_manager"`
+}
+
+type ThirdParty struct {
+	Domain   string   `json:"domain"`
+	Category string   `json:"category"`
+	Company  string   `json:"company,omitempty"`
+	Hosts    []string `json:"hosts"`
+	Kinds    []string `json:"kinds"`
+	Requests int      `json:"requests"`
+}
+
+// Cookie is a cookie set by the page response. Expires is empty for session
+// cookies.
+type Cookie struct {
+	Name     string `json:"name"`
+	Domain   string `json:"domain,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Secure   bool   `json:"secure"`
+	HTTPOnly bool   `json:"http_only"`
+	SameSite string `json:"same_site,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+}
+
+type ConsentManager struct {
+	Present  bool   `json:"present"`
+	Name     string `json:"name,omitempty"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// ConformanceReport checks the page against a target doctype. Mode is the
+// rendering mode browsers pick from the declared doctype: standards,
+// limited-quirks or quirks.
+type ConformanceReport struct {
+	Target     string           `json:"target"`
+	Declared   string           `json:"declared"`
+	Mode       string           `json:"mode"`
+	ModeReason string           `json:"mode_reason,omitempty"`
+	Obsolete   []ObsoleteMarkup `json:"obsolete"`
+	Migration  []string         `json:"migration,omitempty"`
+}
+
+// ObsoleteMarkup is an obsolete element, or an obsolete attribute when
+// Attribute is set. Path locates the first occurrence.
+type ObsoleteMarkup struct {
+	Element     string `json:"element"`
+	Attribute   string `json:"attribute,omitempty"`
+	Count       int    `json:"count"`
+	Path        string `json:"path"`
+	Message     string `json:"message"`
+	Replacement string `json:"replacement"`
+}
+
+// ValidityReport lists authoring errors found while tokenizing the page.
+// Errors is capped; Total counts every error.
+type ValidityReport struct {
+	Errors []ValidityError `json:"errors"`
+	Total  int             `json:"total"`
+}
+
+// ValidityError is an authoring error at a 1-based line and column.
+type ValidityError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// RenderingReport tells whether the page looks like a client-side-rendered
+// shell, and lists the client-side redirects it declares.
+type RenderingReport struct {
+	ClientRendered bool             `json:"client_rendered"`
+	Framework      string           `json:"framework,omitempty"`
+	Signals        []string         `json:"signals,omitempty"`
+	Redirects      []ClientRedirect `json:"redirects,omitempty"`
+	// FollowedRefresh lists the meta refresh targets fetched with
+	// follow_refresh, in order. The rest of the result describes the last.
+	FollowedRefresh []string `json:"followed_refresh,omitempty"`
+}
+
+// ClientRedirect is a meta refresh or a JavaScript location change.
+type ClientRedirect struct {
+	Kind         string  `json:"kind"`
+	Target       string  `json:"target"`
+	URL          string  `json:"url,omitempty"`
+	DelaySeconds float64 `json:"delay_seconds,omitempty"`
+}
+
+// ExposureReport lists personal data and secrets found in the page. Values
+// are masked; Location says where each was found.
+type ExposureReport struct {
+	Findings []Finding     `json:"findings"`
+	Hits     []ExposureHit `json:"hits"`
+}
+
+type ExposureHit struct {
+	Kind     string `json:"kind"`
+	Value    string `json:"value"`
+	Location string `json:"location"`
+}
+
+// CSPReport inventories what a strict Content Security Policy would block.
+// The attribute lists hold the first 50 of each, with long values
+// truncated; the findings carry the full counts.
+type CSPReport struct {
+	InlineScripts     []CSPInlineScript `json:"inline_scripts"`
+	EventHandlers     []InlineAttribute `json:"event_handlers"`
+	JavaScriptURLs    []InlineAttribute `json:"javascript_urls"`
+	InlineStyles      []InlineAttribute `json:"inline_styles"`
+	ScriptsWithoutSRI []string          `json:"scripts_without_sri"`
+	Findings          []Finding         `json:"findings"`
+}
+
+// CSPInlineScript is an executable inline script. Hash is the source
+// expression that allows it in script-src, such as 'sha256-...'.
+type CSPInlineScript struct {
+	Type  string `json:"type,omitempty"`
+	Bytes int    `json:"bytes"`
+	Hash  string `json:"hash"`
+	Eval  bool   `json:"eval,omitempty"`
+}
+
+type InlineAttribute struct {
+	Element   string `json:"element"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+}
+
+// SiteFilesReport describes the site-level files found for the page, with
+// robots.txt first.
+type SiteFilesReport struct {
+	Files    []SiteFile `json:"files"`
+	Findings []Finding  `json:"findings,omitempty"`
+}
+
+// SiteFile is one site-level file. Source is linked (from the page),
+// robots.txt (declared there) or default (its conventional location).
+// Present means it was served; Valid means it passed the structural checks,
+// and Problems lists those it failed. Fields holds the key fields of its
+// kind, such as a feed's title and item count or security.txt's Contact.
+type SiteFile struct {
+	Kind     string            `json:"kind"`
+	URL      string            `json:"url"`
+	Source   string            `json:"source"`
+	Present  bool              `json:"present"`
+	Valid    bool              `json:"valid"`
+	Status   int               `json:"status,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Problems []string          `json:"problems,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// RelationsReport describes AMP and pagination relationships. The chains
+// list the pages reached through rel=next and rel=prev; they are only set
+// when follow_relations is on.
+type RelationsReport struct {
+	AMP       bool      `json:"amp"`
+	Canonical string    `json:"canonical,omitempty"`
+	AMPHTML   string    `json:"amphtml,omitempty"`
+	Next      string    `json:"next,omitempty"`
+	Prev      string    `json:"prev,omitempty"`
+	NextChain []string  `json:"next_chain,omitempty"`
+	PrevChain []string  `json:"prev_chain,omitempty"`
+	Findings  []Finding `json:"findings,omitempty"`
+}
+
+// ImageReport lists image optimization findings. Assets and TotalBytes are
+// only set when fetch_images is on; TotalBytes leaves out images whose size
+// is unknown.
+type ImageReport struct {
+	Count      int          `json:"count"`
+	Findings   []Finding    `json:"findings,omitempty"`
+	Assets     []ImageAsset `json:"assets,omitempty"`
+	TotalBytes int64        `json:"total_bytes,omitempty"`
+}
+
+// ImageAsset is a fetched image. Bytes is omitted when the server did not
+// report a size.
+type ImageAsset struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	Bytes       int64  `json:"bytes,omitempty"`
+	Oversized   bool   `json:"oversized,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// PageWeight estimates the transfer size of the page from the sizes servers
+// report for the document and its scripts, stylesheets, fonts and images.
+// Requests whose size is unknown are counted in UnknownSize and left out of
+// the byte totals.
+type PageWeight struct {
+	TotalBytes     int64                   `json:"total_bytes"`
+	Requests       int                     `json:"requests"`
+	ByType         map[string]WeightBucket `json:"by_type"`
+	FirstParty     WeightBucket            `json:"first_party"`
+	ThirdParty     WeightBucket            `json:"third_party"`
+	UnknownSize    int                     `json:"unknown_size"`
+	RenderBlocking []RenderBlocking        `json:"render_blocking"`
+}
+
+type WeightBucket struct {
+	Requests int   `json:"requests"`
+	Bytes    int64 `json:"bytes"`
+}
+
+// RenderBlocking is a script or stylesheet that delays the first render.
+type RenderBlocking struct {
+	URL        string `json:"url"`
+	Kind       string `json:"kind"`
+	ThirdParty bool   `json:"third_party"`
+	Reason     string `json:"reason"`
+}
+
+// CachingReport audits the caching and compression headers of the document
+// and, with check_asset_caching, of its static assets.
+type CachingReport struct {
+	Document CachePolicy   `json:"document"`
+	Assets   []CachePolicy `json:"assets,omitempty"`
+	Findings []Finding     `json:"findings,omitempty"`
+}
+
+// CachePolicy is what a response's headers say about caching. MaxAgeSeconds
+// is the explicit freshness lifetime, omitted when none is declared. Bytes
+// is the decoded size and GzipBytes the estimated size with gzip for an
+// uncompressed document; both are only set for the document.
+type CachePolicy struct {
+	URL             string `json:"url"`
+	Kind            string `json:"kind,omitempty"`
+	CacheControl    string `json:"cache_control,omitempty"`
+	Expires         string `json:"expires,omitempty"`
+	ETag            string `json:"etag,omitempty"`
+	LastModified    string `json:"last_modified,omitempty"`
+	Vary            string `json:"vary,omitempty"`
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	Cacheable       bool   `json:"cacheable"`
+	MaxAgeSeconds   *int64 `json:"max_age_seconds,omitempty"`
+	Bytes           int64  `json:"bytes,omitempty"`
+	TransferBytes   int64  `json:"transfer_bytes,omitempty"`
+	GzipBytes       int64  `json:"gzip_bytes,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ContentDigest fingerprints the visible text of a page. SHA256 hashes the
+// normalized text, so it only matches identical content; SimHash changes by
+// a few bits for small edits.
+type ContentDigest struct {
+	SHA256  string `json:"sha256"`
+	SimHash string `json:"simhash"`
+	Words   int    `json:"words"`
+}
+
+// SimilarityResult compares two pages, as returned by /api/similarity.
+// Similarity scales the SimHash distance from 100 for identical fingerprints
+// down to 0 at 32 bits, the distance between unrelated pages. Comparable is
+// false when either page has no visible text; the scores are then zero.
+type SimilarityResult struct {
+	A          SimilarityPage `json:"a"`
+	B          SimilarityPage `json:"b"`
+	Comparable bool           `json:"comparable"`
+	Similarity float64        `json:"similarity"`
+	Distance   int            `json:"distance"`
+	Identical  bool           `json:"identical"`
+	Errors     []string       `json:"errors,omitempty"`
+}
+
+type SimilarityPage struct {
+	URL    string         `json:"url,omitempty"`
+	Digest *ContentDigest `json:"digest,omitempty"`
+}
+
+// ExtractedField is the value of a user-defined field. Value is set for
+// single fields and Values for multiple ones; Matches counts the elements
+// the selector matched. Error reports an invalid selector.
+type ExtractedField struct {
+	Value   string   `json:"value,omitempty"`
+	Values  []string `json:"values,omitempty"`
+	Matches int      `json:"matches"`
+	Error   string   `json:"error,omitempty"`
 }
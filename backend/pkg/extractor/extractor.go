@@ -0,0 +1,154 @@
+// Package extractor lets callers plug custom page checks into the analyzer.
+//
+// An Extractor receives the parsed document together with the response
+// metadata and returns a named, JSON-serializable section that is added to
+// the analysis result. Extractors are usually registered on the Default
+// registry from an init function, so a check can live in its own package and
+// be enabled with a blank import:
+//
+//	func init() { extractor.MustRegister(priceCheck{}) }
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Response is the metadata of the fetched document.
+type Response struct {
+	URL        *url.URL
+	StatusCode int
+	Header     http.Header
+}
+
+// Input is what every extractor receives. Doc must be treated as read-only.
+type Input struct {
+	Doc      *goquery.Document
+	Response Response
+	BaseURL  *url.URL
+}
+
+// Extractor computes one named result section from a parsed page.
+type Extractor interface {
+	Name() string
+	Extract(ctx context.Context, in *Input) (any, error)
+}
+
+// TreeOptional is implemented by extractors that also work without a
+// document tree. With the streaming parser Input.Doc is nil, and Run only
+// calls extractors that implement it.
+type TreeOptional interface {
+	Extractor
+	TreeOptional()
+}
+
+var (
+	ErrNoName    = errors.New("extractor name is empty")
+	ErrDuplicate = errors.New("extractor already registered")
+)
+
+// Registry is an ordered set of extractors with unique names.
+type Registry struct {
+	mu   sync.RWMutex
+	list []Extractor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default is the registry the analyzer uses unless told otherwise.
+var Default = NewRegistry()
+
+// Register adds e to the registry.
+func (r *Registry) Register(e Extractor) error {
+	name := e.Name()
+	if name == "" {
+		return ErrNoName
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, x := range r.list {
+		if x.Name() == name {
+			return fmt.Errorf("%w: %s", ErrDuplicate, name)
+		}
+	}
+	r.list = append(r.list, e)
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func (r *Registry) MustRegister(e Extractor) {
+	if err := r.Register(e); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds e to the Default registry.
+func Register(e Extractor) error {
+	return Default.Register(e)
+}
+
+// MustRegister adds e to the Default registry and panics on error.
+func MustRegister(e Extractor) {
+	Default.MustRegister(e)
+}
+
+// Len returns the number of registered extractors.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.list)
+}
+
+// Run executes every extractor in registration order. Each section is
+// encoded as soon as it is produced, so a result that cannot be serialized
+// fails its own extractor instead of the whole response. A failing or
+// panicking extractor is reported in the returned errors and does not stop
+// the others.
+func (r *Registry) Run(ctx context.Context, in *Input) (map[string]json.RawMessage, []error) {
+	r.mu.RLock()
+	list := append([]Extractor(nil), r.list...)
+	r.mu.RUnlock()
+
+	sections := make(map[string]json.RawMessage, len(list))
+	var errs []error
+	for _, e := range list {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("extractor %s: %w", e.Name(), err))
+			continue
+		}
+		if _, ok := e.(TreeOptional); in.Doc == nil && !ok {
+			continue
+		}
+		raw, err := runOne(ctx, e, in)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("extractor %s: %w", e.Name(), err))
+			continue
+		}
+		if raw != nil {
+			sections[e.Name()] = raw
+		}
+	}
+	return sections, errs
+}
+
+func runOne(ctx context.Context, e Extractor, in *Input) (raw json.RawMessage, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	v, err := e.Extract(ctx, in)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
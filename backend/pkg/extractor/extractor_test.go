@@ -0,0 +1,111 @@
+package extractor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chanaka-withanage/page-analyzer/pkg/extractor"
+)
+
+type funcExtractor struct {
+	name string
+	fn   func(in *extractor.Input) (any, error)
+}
+
+func (f funcExtractor) Name() string { return f.name }
+
+func (f funcExtractor) Extract(_ context.Context, in *extractor.Input) (any, error) {
+	return f.fn(in)
+}
+
+func mustDoc(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+	return doc
+}
+
+func TestRegistry_RejectsDuplicatesAndEmptyNames(t *testing.T) {
+	r := extractor.NewRegistry()
+	noop := func(*extractor.Input) (any, error) { return nil, nil }
+
+	if err := r.Register(funcExtractor{name: "price", fn: noop}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register(funcExtractor{name: "price", fn: noop}); !errors.Is(err, extractor.ErrDuplicate) {
+		t.Errorf("expected ErrDuplicate, got %v", err)
+	}
+	if err := r.Register(funcExtractor{name: "", fn: noop}); !errors.Is(err, extractor.ErrNoName) {
+		t.Errorf("expected ErrNoName, got %v", err)
+	}
+	if r.Len() != 1 {
+		t.Errorf("expected 1 registered extractor, got %d", r.Len())
+	}
+}
+
+func TestRegistry_RunCollectsSectionsAndErrors(t *testing.T) {
+	r := extractor.NewRegistry()
+	r.MustRegister(funcExtractor{name: "price", fn: func(in *extractor.Input) (any, error) {
+		return map[string]string{"value": in.Doc.Find(".price").Text()}, nil
+	}})
+	r.MustRegister(funcExtractor{name: "broken", fn: func(*extractor.Input) (any, error) {
+		return nil, errors.New("boom")
+	}})
+	r.MustRegister(funcExtractor{name: "panics", fn: func(*extractor.Input) (any, error) {
+		panic("oops")
+	}})
+	r.MustRegister(funcExtractor{name: "unencodable", fn: func(*extractor.Input) (any, error) {
+		return make(chan int), nil
+	}})
+
+	sections, errs := r.Run(context.Background(), &extractor.Input{
+		Doc: mustDoc(t, `<p class="price">9.99</p>`),
+	})
+
+	var price struct{ Value string }
+	if err := json.Unmarshal(sections["price"], &price); err != nil || price.Value != "9.99" {
+		t.Errorf("unexpected price section %s (err %v)", sections["price"], err)
+	}
+	if len(sections) != 1 {
+		t.Errorf("expected only the price section, got %v", sections)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+	for i, name := range []string{"broken", "panics", "unencodable"} {
+		if !strings.Contains(errs[i].Error(), name) {
+			t.Errorf("expected error %d to name %q, got %v", i, name, errs[i])
+		}
+	}
+}
+
+type treeOptional struct{ funcExtractor }
+
+func (treeOptional) TreeOptional() {}
+
+func TestRegistry_RunWithoutTreeSkipsTreeExtractors(t *testing.T) {
+	r := extractor.NewRegistry()
+	r.MustRegister(funcExtractor{name: "price", fn: func(in *extractor.Input) (any, error) {
+		return in.Doc.Find(".price").Text(), nil
+	}})
+	r.MustRegister(treeOptional{funcExtractor{name: "status", fn: func(in *extractor.Input) (any, error) {
+		return in.Response.StatusCode, nil
+	}}})
+
+	sections, errs := r.Run(context.Background(), &extractor.Input{Response: extractor.Response{StatusCode: 200}})
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if _, ok := sections["price"]; ok {
+		t.Errorf("expected the tree extractor to be skipped, got %v", sections)
+	}
+	if string(sections["status"]) != "200" {
+		t.Errorf("expected the status section, got %v", sections)
+	}
+}